@@ -0,0 +1,334 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"html"
+	"image"
+	imagecolor "image/color"
+	"image/gif"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// lastFrameDelay is how long the last frame is shown before an animation starts over
+const lastFrameDelay = 2 * time.Second
+
+// SVG cell size, for font size 14
+const svgCellWidth, svgCellHeight, svgBaseline = 8.4, 17, 13
+
+// GIF cell size (before scaling), a 5x7 glyph with spacing and room for underline
+const gifCellWidth, gifCellHeight, gifScale = 6, 10, 2
+
+// AnimatedSVG renders frames as an SVG image animated with CSS keyframes. Repeated frames are
+// skipped, and no frame is shown longer than idleLimit (unless 0).
+func AnimatedSVG(frames []Frame, idleLimit time.Duration) string {
+	frames = animation(frames, idleLimit)
+	columns, rows := frameSize(frames)
+	width, height := float64(columns)*svgCellWidth, rows*svgCellHeight
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%d" viewBox="0 0 %g %d"`+
+		` font-family="monospace" font-size="14" xml:space="preserve">`+"\n", width, height, width, height)
+	if len(frames) > 1 {
+		total := frames[len(frames)-1].Time + lastFrameDelay
+		b.WriteString("<style>@keyframes termscreen {")
+		for i, frame := range frames {
+			fmt.Fprintf(&b, " %.3f%% { transform: translateY(%dpx) }", float64(frame.Time*100)/float64(total), -i*height)
+		}
+		fmt.Fprintf(&b, " }\n.termscreen { animation: termscreen %.3fs steps(1, end) infinite }</style>\n", total.Seconds())
+	}
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", defaultBackground.hex(defaultBackground))
+	fmt.Fprintf(&b, `<g class="termscreen" fill="%s">`+"\n", defaultForeground.hex(defaultForeground))
+	for i, frame := range frames {
+		fmt.Fprintf(&b, `<g transform="translate(0 %d)">`+"\n", i*height)
+		for row, line := range frame.Lines {
+			svgLine(&b, line, row*svgCellHeight)
+		}
+		b.WriteString("</g>\n")
+	}
+	b.WriteString("</g>\n</svg>\n")
+	return b.String()
+}
+
+func svgLine(b *strings.Builder, line string, y int) {
+	switch size := lineSize(line); size {
+	case doubleWidth:
+		b.WriteString(`<g transform="scale(2 1)">` + "\n")
+		svgRuns(b, line, y)
+		b.WriteString("</g>\n")
+	case doubleHeightTop, doubleHeightBottom: // half of the line, scaled, clipped to the row
+		shift := 0
+		if size == doubleHeightBottom {
+			shift = -svgCellHeight
+		}
+		fmt.Fprintf(b, `<svg y="%d" height="%d" overflow="hidden"><g transform="translate(0 %d) scale(2)">`+"\n",
+			y, svgCellHeight, shift)
+		svgRuns(b, line, 0)
+		b.WriteString("</g></svg>\n")
+	default:
+		svgRuns(b, line, y)
+	}
+}
+
+func svgRuns(b *strings.Builder, line string, y int) {
+	column := 0
+	for _, run := range runs(line) {
+		x := float64(column) * svgCellWidth
+		columns := utf8.RuneCountInString(run.text)
+		column += columns
+		fg, bg := run.attr.colors()
+		if bg != defaultColor {
+			fmt.Fprintf(b, `<rect x="%g" y="%d" width="%g" height="%d" fill="%s"/>`+"\n",
+				x, y, float64(columns)*svgCellWidth, svgCellHeight, bg.hex(defaultBackground))
+		}
+		if run.attr.hidden || strings.TrimSpace(run.text) == "" {
+			continue
+		}
+		attrs := ""
+		if fg != defaultColor {
+			attrs += ` fill="` + fg.hex(defaultForeground) + `"`
+		}
+		if run.attr.bold {
+			attrs += ` font-weight="bold"`
+		}
+		if run.attr.faint {
+			attrs += ` opacity="0.5"`
+		}
+		if run.attr.italic {
+			attrs += ` font-style="italic"`
+		}
+		if run.attr.underline {
+			attrs += ` text-decoration="underline"`
+		} else if run.attr.strike {
+			attrs += ` text-decoration="line-through"`
+		}
+		fmt.Fprintf(b, `<text x="%g" y="%d"%s>%s</text>`+"\n", x, y+svgBaseline, attrs, html.EscapeString(run.text))
+	}
+}
+
+// AnimatedGIF writes frames as an animated GIF image, with a built-in font for ASCII characters.
+// Repeated frames are skipped, and no frame is shown longer than idleLimit (unless 0).
+func AnimatedGIF(writer io.Writer, frames []Frame, idleLimit time.Duration) error {
+	frames = animation(frames, idleLimit)
+	columns, rows := frameSize(frames)
+	bounds := image.Rect(0, 0, columns*gifCellWidth*gifScale, rows*gifCellHeight*gifScale)
+	palette := make(imagecolor.Palette, 256)
+	for i := range palette {
+		r, g, b := color(i).rgb(defaultForeground)
+		palette[i] = imagecolor.RGBA{r, g, b, 0xff}
+	}
+	anim := &gif.GIF{}
+	for i, frame := range frames {
+		img := image.NewPaletted(bounds, palette)
+		for row, line := range frame.Lines {
+			gifLine(img, line, row)
+		}
+		delay := lastFrameDelay
+		if i+1 < len(frames) {
+			delay = frames[i+1].Time - frame.Time
+		}
+		anim.Image = append(anim.Image, img)
+		anim.Delay = append(anim.Delay, int(delay/(10*time.Millisecond)))
+	}
+	return gif.EncodeAll(writer, anim)
+}
+
+func gifLine(img *image.Paletted, line string, row int) {
+	index := func(c color, def color) uint8 {
+		r, g, b := c.rgb(def)
+		return uint8(img.Palette.Index(imagecolor.RGBA{r, g, b, 0xff}))
+	}
+	size := lineSize(line)
+	cellWidth := gifCellWidth
+	if size != 0 {
+		cellWidth *= 2
+	}
+	column := 0
+	for _, run := range runs(line) {
+		fg, bg := run.attr.colors()
+		fgIndex, bgIndex := index(fg, defaultForeground), index(bg, defaultBackground)
+		for _, r := range run.text {
+			x0, y0 := column*cellWidth, row*gifCellHeight
+			column++
+			for py := 0; py < gifCellHeight; py++ {
+				for px := 0; px < cellWidth; px++ {
+					x, y := px*gifCellWidth/cellWidth, py // pixel of the single-size glyph
+					if size == doubleHeightTop {
+						y = py / 2
+					} else if size == doubleHeightBottom {
+						y = (py + gifCellHeight) / 2
+					}
+					pixel := bgIndex
+					if !run.attr.hidden && (glyphPixel(r, x, y-1) || run.attr.bold && glyphPixel(r, x-1, y-1) ||
+						run.attr.underline && y == gifCellHeight-1 || run.attr.strike && y == 4) {
+						pixel = fgIndex
+					}
+					for sy := 0; sy < gifScale; sy++ {
+						for sx := 0; sx < gifScale; sx++ {
+							img.SetColorIndex((x0+px)*gifScale+sx, (y0+py)*gifScale+sy, pixel)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func glyphPixel(r rune, x, y int) bool {
+	if x < 0 || x >= 5 || y < 0 || y >= 7 {
+		return false
+	}
+	if r == ' ' {
+		return false
+	}
+	if r < 0x20 || r > 0x7e {
+		return x == 0 || x == 4 || y == 0 || y == 6
+	}
+	return font5x7[r-0x20][y]&(0x10>>x) != 0
+}
+
+// animation returns frames without repeated screens, starting at time 0, and with at most
+// idleLimit (unless 0) between frames
+func animation(frames []Frame, idleLimit time.Duration) []Frame {
+	result := []Frame{}
+	var previous time.Duration // original time of last frame in result
+	for _, frame := range frames {
+		n := len(result)
+		if n == 0 {
+			result = append(result, Frame{Time: 0, Lines: frame.Lines})
+		} else if !equal(frame.Lines, result[n-1].Lines) {
+			delay := frame.Time - previous
+			if idleLimit > 0 && delay > idleLimit {
+				delay = idleLimit
+			}
+			result = append(result, Frame{Time: result[n-1].Time + delay, Lines: frame.Lines})
+		} else {
+			continue
+		}
+		previous = frame.Time
+	}
+	return result
+}
+
+// frameSize returns the number of columns and rows needed to show all frames
+func frameSize(frames []Frame) (int, int) {
+	columns, rows := 1, 1
+	for _, frame := range frames {
+		rows = max(rows, len(frame.Lines))
+		for _, line := range frame.Lines {
+			if lineSize(line) != 0 {
+				columns = max(columns, 2*length(line))
+			} else {
+				columns = max(columns, length(line))
+			}
+		}
+	}
+	return columns, rows
+}
+
+// font5x7 has ASCII characters 0x20-0x7e, one byte per row with the leftmost pixel as 0x10
+var font5x7 = [][7]uint8{
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}, // space
+	{0x04, 0x04, 0x04, 0x04, 0x04, 0x00, 0x04}, // !
+	{0x0a, 0x0a, 0x0a, 0x00, 0x00, 0x00, 0x00}, // "
+	{0x0a, 0x0a, 0x1f, 0x0a, 0x1f, 0x0a, 0x0a}, // #
+	{0x04, 0x0f, 0x14, 0x0e, 0x05, 0x1e, 0x04}, // $
+	{0x18, 0x19, 0x02, 0x04, 0x08, 0x13, 0x03}, // %
+	{0x0c, 0x12, 0x14, 0x08, 0x15, 0x12, 0x0d}, // &
+	{0x04, 0x04, 0x08, 0x00, 0x00, 0x00, 0x00}, // '
+	{0x02, 0x04, 0x08, 0x08, 0x08, 0x04, 0x02}, // (
+	{0x08, 0x04, 0x02, 0x02, 0x02, 0x04, 0x08}, // )
+	{0x00, 0x04, 0x15, 0x0e, 0x15, 0x04, 0x00}, // *
+	{0x00, 0x04, 0x04, 0x1f, 0x04, 0x04, 0x00}, // +
+	{0x00, 0x00, 0x00, 0x00, 0x0c, 0x04, 0x08}, // ,
+	{0x00, 0x00, 0x00, 0x1f, 0x00, 0x00, 0x00}, // -
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x0c, 0x0c}, // .
+	{0x00, 0x01, 0x02, 0x04, 0x08, 0x10, 0x00}, // /
+	{0x0e, 0x11, 0x13, 0x15, 0x19, 0x11, 0x0e}, // 0
+	{0x04, 0x0c, 0x04, 0x04, 0x04, 0x04, 0x0e}, // 1
+	{0x0e, 0x11, 0x01, 0x02, 0x04, 0x08, 0x1f}, // 2
+	{0x1f, 0x02, 0x04, 0x02, 0x01, 0x11, 0x0e}, // 3
+	{0x02, 0x06, 0x0a, 0x12, 0x1f, 0x02, 0x02}, // 4
+	{0x1f, 0x10, 0x1e, 0x01, 0x01, 0x11, 0x0e}, // 5
+	{0x06, 0x08, 0x10, 0x1e, 0x11, 0x11, 0x0e}, // 6
+	{0x1f, 0x01, 0x02, 0x04, 0x08, 0x08, 0x08}, // 7
+	{0x0e, 0x11, 0x11, 0x0e, 0x11, 0x11, 0x0e}, // 8
+	{0x0e, 0x11, 0x11, 0x0f, 0x01, 0x02, 0x0c}, // 9
+	{0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x0c, 0x00}, // :
+	{0x00, 0x0c, 0x0c, 0x00, 0x0c, 0x04, 0x08}, // ;
+	{0x02, 0x04, 0x08, 0x10, 0x08, 0x04, 0x02}, // <
+	{0x00, 0x00, 0x1f, 0x00, 0x1f, 0x00, 0x00}, // =
+	{0x08, 0x04, 0x02, 0x01, 0x02, 0x04, 0x08}, // >
+	{0x0e, 0x11, 0x01, 0x02, 0x04, 0x00, 0x04}, // ?
+	{0x0e, 0x11, 0x01, 0x0d, 0x15, 0x15, 0x0e}, // @
+	{0x0e, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11}, // A
+	{0x1e, 0x11, 0x11, 0x1e, 0x11, 0x11, 0x1e}, // B
+	{0x0e, 0x11, 0x10, 0x10, 0x10, 0x11, 0x0e}, // C
+	{0x1c, 0x12, 0x11, 0x11, 0x11, 0x12, 0x1c}, // D
+	{0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x1f}, // E
+	{0x1f, 0x10, 0x10, 0x1e, 0x10, 0x10, 0x10}, // F
+	{0x0e, 0x11, 0x10, 0x17, 0x11, 0x11, 0x0f}, // G
+	{0x11, 0x11, 0x11, 0x1f, 0x11, 0x11, 0x11}, // H
+	{0x0e, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e}, // I
+	{0x07, 0x02, 0x02, 0x02, 0x02, 0x12, 0x0c}, // J
+	{0x11, 0x12, 0x14, 0x18, 0x14, 0x12, 0x11}, // K
+	{0x10, 0x10, 0x10, 0x10, 0x10, 0x10, 0x1f}, // L
+	{0x11, 0x1b, 0x15, 0x15, 0x11, 0x11, 0x11}, // M
+	{0x11, 0x11, 0x19, 0x15, 0x13, 0x11, 0x11}, // N
+	{0x0e, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e}, // O
+	{0x1e, 0x11, 0x11, 0x1e, 0x10, 0x10, 0x10}, // P
+	{0x0e, 0x11, 0x11, 0x11, 0x15, 0x12, 0x0d}, // Q
+	{0x1e, 0x11, 0x11, 0x1e, 0x14, 0x12, 0x11}, // R
+	{0x0f, 0x10, 0x10, 0x0e, 0x01, 0x01, 0x1e}, // S
+	{0x1f, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, // T
+	{0x11, 0x11, 0x11, 0x11, 0x11, 0x11, 0x0e}, // U
+	{0x11, 0x11, 0x11, 0x11, 0x11, 0x0a, 0x04}, // V
+	{0x11, 0x11, 0x11, 0x15, 0x15, 0x15, 0x0a}, // W
+	{0x11, 0x11, 0x0a, 0x04, 0x0a, 0x11, 0x11}, // X
+	{0x11, 0x11, 0x11, 0x0a, 0x04, 0x04, 0x04}, // Y
+	{0x1f, 0x01, 0x02, 0x04, 0x08, 0x10, 0x1f}, // Z
+	{0x0e, 0x08, 0x08, 0x08, 0x08, 0x08, 0x0e}, // [
+	{0x00, 0x10, 0x08, 0x04, 0x02, 0x01, 0x00}, // \
+	{0x0e, 0x02, 0x02, 0x02, 0x02, 0x02, 0x0e}, // ]
+	{0x04, 0x0a, 0x11, 0x00, 0x00, 0x00, 0x00}, // ^
+	{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x1f}, // _
+	{0x08, 0x04, 0x02, 0x00, 0x00, 0x00, 0x00}, // `
+	{0x00, 0x00, 0x0e, 0x01, 0x0f, 0x11, 0x0f}, // a
+	{0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x1e}, // b
+	{0x00, 0x00, 0x0e, 0x10, 0x10, 0x11, 0x0e}, // c
+	{0x01, 0x01, 0x0d, 0x13, 0x11, 0x11, 0x0f}, // d
+	{0x00, 0x00, 0x0e, 0x11, 0x1f, 0x10, 0x0e}, // e
+	{0x06, 0x09, 0x08, 0x1c, 0x08, 0x08, 0x08}, // f
+	{0x00, 0x0f, 0x11, 0x11, 0x0f, 0x01, 0x0e}, // g
+	{0x10, 0x10, 0x16, 0x19, 0x11, 0x11, 0x11}, // h
+	{0x04, 0x00, 0x0c, 0x04, 0x04, 0x04, 0x0e}, // i
+	{0x02, 0x00, 0x06, 0x02, 0x02, 0x12, 0x0c}, // j
+	{0x10, 0x10, 0x12, 0x14, 0x18, 0x14, 0x12}, // k
+	{0x0c, 0x04, 0x04, 0x04, 0x04, 0x04, 0x0e}, // l
+	{0x00, 0x00, 0x1a, 0x15, 0x15, 0x11, 0x11}, // m
+	{0x00, 0x00, 0x16, 0x19, 0x11, 0x11, 0x11}, // n
+	{0x00, 0x00, 0x0e, 0x11, 0x11, 0x11, 0x0e}, // o
+	{0x00, 0x00, 0x1e, 0x11, 0x1e, 0x10, 0x10}, // p
+	{0x00, 0x00, 0x0d, 0x13, 0x0f, 0x01, 0x01}, // q
+	{0x00, 0x00, 0x16, 0x19, 0x10, 0x10, 0x10}, // r
+	{0x00, 0x00, 0x0e, 0x10, 0x0e, 0x01, 0x1e}, // s
+	{0x08, 0x08, 0x1c, 0x08, 0x08, 0x09, 0x06}, // t
+	{0x00, 0x00, 0x11, 0x11, 0x11, 0x13, 0x0d}, // u
+	{0x00, 0x00, 0x11, 0x11, 0x11, 0x0a, 0x04}, // v
+	{0x00, 0x00, 0x11, 0x11, 0x15, 0x15, 0x0a}, // w
+	{0x00, 0x00, 0x11, 0x0a, 0x04, 0x0a, 0x11}, // x
+	{0x00, 0x00, 0x11, 0x11, 0x0f, 0x01, 0x0e}, // y
+	{0x00, 0x00, 0x1f, 0x02, 0x04, 0x08, 0x1f}, // z
+	{0x02, 0x04, 0x04, 0x08, 0x04, 0x04, 0x02}, // {
+	{0x04, 0x04, 0x04, 0x04, 0x04, 0x04, 0x04}, // |
+	{0x08, 0x04, 0x04, 0x02, 0x04, 0x04, 0x08}, // }
+	{0x00, 0x00, 0x08, 0x15, 0x02, 0x00, 0x00}, // ~
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"bytes"
+	"image/gif"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testFrames = []Frame{
+	{Time: time.Second, Lines: []string{"one"}},
+	{Time: 2 * time.Second, Lines: []string{"one"}},
+	{Time: 3 * time.Second, Lines: []string{"\x1b[31mtwo", "lines"}},
+	{Time: 60 * time.Second, Lines: []string{"<3>"}},
+}
+
+func TestAnimation(t *testing.T) {
+	frames := animation(testFrames, 5*time.Second)
+
+	got := []string{}
+	for _, frame := range frames {
+		got = append(got, frame.Time.String()+"="+strings.Join(frame.Lines, ","))
+	}
+	assertEqualsStr(t, "0s=one 2s=\x1b[31mtwo,lines 7s=<3>", strings.Join(got, " "))
+}
+
+func TestAnimatedSVG(t *testing.T) {
+	svg := AnimatedSVG(testFrames, 5*time.Second)
+
+	assertTrue(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="42" height="34"`))
+	assertTrue(t, strings.Contains(svg, "@keyframes termscreen { 0.000% { transform: translateY(0px) } 22.222% { transform: translateY(-34px) } 77.778% { transform: translateY(-68px) } }"))
+	assertTrue(t, strings.Contains(svg, "animation: termscreen 9.000s steps(1, end) infinite"))
+	assertTrue(t, strings.Contains(svg, `<text x="0" y="13" fill="#cd0000">two</text>`))
+	assertTrue(t, strings.Contains(svg, `<g transform="translate(0 68)">`+"\n"+`<text x="0" y="13">&lt;3&gt;</text>`))
+}
+
+func TestAnimatedGIF(t *testing.T) {
+	var buf bytes.Buffer
+	err := AnimatedGIF(&buf, testFrames, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	anim, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, len(anim.Image))
+	assertEquals(t, 200, anim.Delay[0])
+	assertEquals(t, 5700, anim.Delay[1])
+	assertEquals(t, 200, anim.Delay[2])
+	bounds := anim.Image[0].Bounds()
+	assertEquals(t, 5*gifCellWidth*gifScale, bounds.Dx())
+	assertEquals(t, 2*gifCellHeight*gifScale, bounds.Dy())
+	// top of 't' in "two"
+	r, g, b, _ := anim.Image[1].At(1*gifScale, 1*gifScale).RGBA()
+	assertEquals(t, 0xcdcd, int(r))
+	assertEquals(t, 0, int(g+b))
+}
+
+func TestGlyphPixel(t *testing.T) {
+	assertTrue(t, glyphPixel('|', 2, 0))
+	assertTrue(t, !glyphPixel('|', 1, 0))
+	assertTrue(t, !glyphPixel('|', 2, 7))
+	assertTrue(t, glyphPixel('✓', 0, 3))
+	assertTrue(t, !glyphPixel('✓', 2, 3))
+}
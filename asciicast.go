@@ -0,0 +1,134 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ReadAsciicast reads an asciicast v2 recording (as made by asciinema), keeping the output and
+// resize events
+func ReadAsciicast(reader io.Reader) (Recording, error) {
+	decoder := json.NewDecoder(reader)
+	var header struct {
+		Version int `json:"version"`
+		Width   int `json:"width"`
+		Height  int `json:"height"`
+	}
+	if err := decoder.Decode(&header); err != nil {
+		return Recording{}, fmt.Errorf("reading asciicast header: %w", err)
+	}
+	if header.Version != 2 {
+		return Recording{}, fmt.Errorf("unsupported asciicast version %d", header.Version)
+	}
+	recording := Recording{Width: header.Width, Height: header.Height}
+	for {
+		var event []interface{}
+		err := decoder.Decode(&event)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return recording, fmt.Errorf("reading asciicast event %d: %w", len(recording.Events)+1, err)
+		}
+		if len(event) != 3 {
+			return recording, fmt.Errorf("invalid asciicast event: %v", event)
+		}
+		seconds, ok0 := event[0].(float64)
+		code, ok1 := event[1].(string)
+		data, ok2 := event[2].(string)
+		if !ok0 || !ok1 || !ok2 {
+			return recording, fmt.Errorf("invalid asciicast event: %v", event)
+		}
+		switch code {
+		case "o":
+			recording.Events = append(recording.Events, Event{Time: seconds2duration(seconds), Data: data})
+		case "r": // Resize, "COLUMNSxROWS"
+			var columns, rows int
+			if _, err := fmt.Sscanf(data, "%dx%d", &columns, &rows); err != nil {
+				return recording, fmt.Errorf("invalid asciicast resize event: %v", event)
+			}
+			resize := fmt.Sprintf("\x1b[8;%d;%dt", rows, columns)
+			recording.Events = append(recording.Events, Event{Time: seconds2duration(seconds), Data: resize})
+		}
+	}
+	return recording, nil
+}
+
+func seconds2duration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// AsciicastWriter records output written to it as an asciicast v2 recording, with the time of each
+// write
+type AsciicastWriter struct {
+	writer  io.Writer
+	start   time.Time
+	now     func() time.Time
+	pending string // incomplete UTF-8 character at end of last Write
+}
+
+// NewAsciicastWriter writes the asciicast header. Size 0 is recorded as 80x24, as players need a size.
+func NewAsciicastWriter(writer io.Writer, width, height int) (*AsciicastWriter, error) {
+	w := &AsciicastWriter{writer: writer, now: time.Now}
+	w.start = w.now()
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+	header, err := json.Marshal(struct {
+		Version   int   `json:"version"`
+		Width     int   `json:"width"`
+		Height    int   `json:"height"`
+		Timestamp int64 `json:"timestamp"`
+	}{2, width, height, w.start.Unix()})
+	if err != nil {
+		return nil, err
+	}
+	_, err = fmt.Fprintf(writer, "%s\n", header)
+	return w, err
+}
+
+// Write records p as an output event
+func (w *AsciicastWriter) Write(p []byte) (int, error) {
+	text := w.pending + string(p)
+	i := incompleteRune(text)
+	w.pending = text[i:]
+	if i == 0 {
+		return len(p), nil
+	}
+	return len(p), w.event(text[:i])
+}
+
+// Flush records any incomplete character held back by Write
+func (w *AsciicastWriter) Flush() error {
+	if w.pending == "" {
+		return nil
+	}
+	text := w.pending
+	w.pending = ""
+	return w.event(text)
+}
+
+func (w *AsciicastWriter) event(data string) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(data); err != nil {
+		return err
+	}
+	seconds := w.now().Sub(w.start).Seconds()
+	_, err := fmt.Fprintf(w.writer, "[%.6f, \"o\", %s]\n", seconds, bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+	return err
+}
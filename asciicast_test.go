@@ -0,0 +1,90 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadAsciicast(t *testing.T) {
+	cast := `{"version": 2, "width": 80, "height": 24, "timestamp": 1504467315}
+[0.248848, "o", "\u001b[1;31mHello \u001b[32mWorld!\u001b[0m\n"]
+[1.001376, "i", "q"]
+[1.5, "o", "bye"]
+`
+	recording, err := ReadAsciicast(strings.NewReader(cast))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 80, recording.Width)
+	assertEquals(t, 24, recording.Height)
+	assertEquals(t, 2, len(recording.Events))
+	assertEquals(t, int(1500*time.Millisecond), int(recording.Events[1].Time))
+	assertEqualsStr(t, "bye", recording.Events[1].Data)
+}
+
+func TestReadAsciicastResize(t *testing.T) {
+	cast := `{"version": 2, "width": 10, "height": 2}
+[0.1, "o", "0123456789"]
+[0.2, "r", "5x2"]
+[0.3, "o", "!"]
+`
+	recording, err := ReadAsciicast(strings.NewReader(cast))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEqualsStr(t, "\x1b[8;2;5t", recording.Events[1].Data)
+	assertEqualsStr(t, "0123!", strings.Join(recording.Screen(), ","))
+}
+
+func TestReadAsciicastInvalid(t *testing.T) {
+	for _, cast := range []string{"", `{"version": 1}`, "{\"version\": 2}\n[0.1, \"o\"]", "{\"version\": 2}\n[\"o\", 1, 2]", "{\"version\": 2}\n[0.1, \"r\", \"big\"]"} {
+		_, err := ReadAsciicast(strings.NewReader(cast))
+		if err == nil {
+			t.Errorf("Expected error for %q", cast)
+		}
+	}
+}
+
+func TestAsciicastWriter(t *testing.T) {
+	var buf strings.Builder
+	writer, err := NewAsciicastWriter(&buf, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := writer.start
+	writer.now = func() time.Time { return start.Add(1500 * time.Millisecond) }
+	writer.Write([]byte("<a> \xe2\x86"))
+	writer.Write([]byte("\x91\x1b[m\n"))
+
+	recording, err := ReadAsciicast(strings.NewReader(buf.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 10, recording.Width)
+	assertEquals(t, 24, recording.Height)
+	assertEquals(t, 2, len(recording.Events))
+	assertEqualsStr(t, "<a> ", recording.Events[0].Data)
+	assertEqualsStr(t, "↑\x1b[m\n", recording.Events[1].Data)
+	assertEquals(t, int(1500*time.Millisecond), int(recording.Events[1].Time))
+	assertTrue(t, strings.Contains(buf.String(), "\n[1.500000, \"o\", \"<a> \"]\n"))
+}
+
+func TestAsciicastWriterFlush(t *testing.T) {
+	var buf strings.Builder
+	writer, _ := NewAsciicastWriter(&buf, 80, 24)
+	writer.Write([]byte("\xe2\x86"))
+
+	assertEquals(t, 1, strings.Count(buf.String(), "\n"))
+	writer.Flush()
+	assertEquals(t, 2, strings.Count(buf.String(), "\n"))
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DisableBCE makes erased cells blank, instead of having the current background color
+// (background color erase, like xterm), whatever the profile. Without a width (WithSize), cells are never colored, as
+// lines have no end to erase to.
+func DisableBCE() Option {
+	return func(o *opt) {
+		o.noBCE = true
+	}
+}
+
+// erasedBackground returns the SGR code for the background of erased cells, or "" if they are
+// blank
+func (terminal *Terminal) erasedBackground() string {
+	if profile := terminal.opt.profile; terminal.opt.noBCE || terminal.opt.width == 0 ||
+		profile != nil && !profile.bce {
+		return ""
+	}
+	return background(terminal.style)
+}
+
+// background returns the SGR code for the background color of style, or "" if it is the default
+func background(style string) string {
+	attr := newAttributes()
+	for _, code := range ansiStyleCodes.FindAllString(style, -1) {
+		attr.apply(code)
+	}
+	switch c := attr.bg; {
+	case c == defaultColor:
+		return ""
+	case c&rgbColor != 0:
+		return fmt.Sprintf("\x1b[48;2;%d;%d;%dm", uint8(c>>16), uint8(c>>8), uint8(c))
+	case c < 8:
+		return fmt.Sprintf("\x1b[%dm", 40+c)
+	case c < 16:
+		return fmt.Sprintf("\x1b[%dm", 100+c-8)
+	}
+	return fmt.Sprintf("\x1b[48;5;%dm", attr.bg)
+}
+
+// colored returns n spaces with background bg
+func colored(bg string, n int) string {
+	return bg + strings.Repeat(" ", max(0, n)) + "\x1b[m"
+}
+
+// columns returns the number of columns of row y, as reported to programs if unlimited
+func (terminal *Terminal) columns(y int) int {
+	if width := terminal.lineWidth(y); width > 0 {
+		return width
+	}
+	columns, _ := terminal.size()
+	return columns
+}
+
+// lastRow returns the row after the screen, or after the last line if the height is unlimited
+func (terminal *Terminal) lastRow() int {
+	if terminal.opt.height > 0 {
+		return terminal.top + terminal.opt.height
+	}
+	return len(terminal.screen)
+}
+
+// colorErased fills cells erased by "\x1b[J" or "\x1b[K" (mode 0 to end, 1 to beginning, 2 all)
+// with the background color
+func (terminal *Terminal) colorErased(display bool, mode int, last int) {
+	bg := terminal.erasedBackground()
+	if bg == "" || mode > 2 {
+		return
+	}
+	x, y := terminal.x, terminal.y
+	first := y
+	if display && mode != 0 {
+		first = terminal.top
+	}
+	if !display || mode == 1 {
+		last = y + 1
+	}
+	for row := first; row < last; row++ {
+		if mode == 2 || mode == 0 && row > y || mode == 1 && row < y {
+			terminal.screen = print(terminal.screen, colored(bg, terminal.columns(row)), 0, row)
+		} else if mode == 0 {
+			terminal.screen = print(terminal.screen, colored(bg, terminal.columns(row)-x), x, row)
+		} else {
+			terminal.screen = print(terminal.screen, colored(bg, x), 0, row)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBackgroundColorErase(t *testing.T) {
+	terminal := NewTerminal(WithSize(6, 3))
+	terminal.Write([]byte("\x1b[44mbar\x1b[K\x1b[m"))
+
+	assertEqualsStr(t, "bar   ", strings.Join(stripStyles(terminal.Lines()), ","))
+	assertEqualsStr(t, "######", backgrounds(terminal.Lines()))
+
+	terminal.Write([]byte("\r\n\x1b[1;42mab\x1b[2J\x1b[m"))
+	assertEqualsStr(t, "      ,      ,      ", strings.Join(stripStyles(terminal.Lines()), ","))
+	assertEqualsStr(t, "######,######,######", backgrounds(terminal.Lines()))
+}
+
+func TestBackgroundColorEraseLine(t *testing.T) {
+	terminal := NewTerminal(WithSize(6, 2))
+	terminal.Write([]byte("abcdef\x1b[4G\x1b[48;5;200m\x1b[1K\x1b[2X\x1b[m\r\n\x1b[48;2;1;2;3m\x1b[2K"))
+
+	assertEqualsStr(t, "     f,      ", strings.Join(stripStyles(terminal.Lines()), ","))
+	assertEqualsStr(t, "#####.,######", backgrounds(terminal.Lines()))
+}
+
+func TestBackgroundColorScroll(t *testing.T) {
+	terminal := NewTerminal(WithSize(4, 2))
+	terminal.Write([]byte("one\r\ntwo\x1b[101m\r\n\x1b[m"))
+
+	assertEqualsStr(t, "two,    ", strings.Join(stripStyles(terminal.Screen()), ","))
+	assertEqualsStr(t, "...,####", backgrounds(terminal.Screen()))
+}
+
+func TestDisableBCE(t *testing.T) {
+	terminal := NewTerminal(WithSize(6, 2), DisableBCE())
+	terminal.Write([]byte("abcdef\x1b[3G\x1b[44m\x1b[K\x1b[1G\x1b[X\x1b[m"))
+
+	assertEqualsStr(t, " b", strings.Join(stripStyles(terminal.Lines()), ","))
+	assertEqualsStr(t, "..", backgrounds(terminal.Lines()))
+}
+
+// backgrounds shows cells with a background color as #
+func backgrounds(lines []string) string {
+	result := []string{}
+	for _, line := range lines {
+		cells := ""
+		for _, run := range runs(line) {
+			c := "."
+			if _, bg := run.attr.colors(); bg != defaultColor {
+				c = "#"
+			}
+			cells += strings.Repeat(c, length(run.text))
+		}
+		result = append(result, cells)
+	}
+	return strings.Join(result, ",")
+}
+
+func TestEraseLineAfterStyleOnly(t *testing.T) {
+	// grep --color output, erasing at a column the line does not reach
+	lines := Capture(strings.NewReader("a\t\x1b[01;31m\x1b[Kfoo\x1b[m\x1b[K\n"))
+
+	assertEquals(t, 1, len(lines))
+	assertEqualsStr(t, "a       \x1b[01;31mfoo", lines[0])
+}
+
+func TestBackgroundColorLastLineWithoutNewline(t *testing.T) {
+	input := "1\n2\n3\n\x1b[44mfour"
+	terminal := NewTerminal(WithSize(5, 4))
+	terminal.Write([]byte(input))
+	lines := Capture(strings.NewReader(input), WithSize(5, 4))
+
+	assertEquals(t, 4, len(lines))
+	assertEqualsStr(t, strings.Join(terminal.Lines(), ","), strings.Join(lines, ","))
+}
+
+func TestBackgroundColorEraseUnlimitedWidth(t *testing.T) {
+	lines := Capture(strings.NewReader("\x1b[41mred\x1b[K\x1b[m\n\x1b[44mab\x1b[1K\x1b[2K\x1b[Jc\x1b[X\n"))
+
+	assertEquals(t, 2, len(lines))
+	assertEqualsStr(t, "\x1b[41mred", lines[0])
+	assertEqualsStr(t, "###,..#", backgrounds(lines))
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// charsets maps characters of 94-character sets, by designation (e.g. "0" in "\x1b(0").
+// Characters not in the map are printed as ASCII.
+var charsets = map[string]map[rune]rune{
+	"0": { // DEC Special Graphics (line drawing)
+		'_': ' ', '`': '◆', 'a': '▒', 'b': '␉', 'c': '␌', 'd': '␍', 'e': '␊', 'f': '°',
+		'g': '±', 'h': '␤', 'i': '␋', 'j': '┘', 'k': '┐', 'l': '┌', 'm': '└', 'n': '┼',
+		'o': '⎺', 'p': '⎻', 'q': '─', 'r': '⎼', 's': '⎽', 't': '├', 'u': '┤', 'v': '┴',
+		'w': '┬', 'x': '│', 'y': '≤', 'z': '≥', '{': 'π', '|': '≠', '}': '£', '~': '·',
+	},
+	"A": { // United Kingdom
+		'#': '£',
+	},
+	"<":  decSupplemental,
+	"%5": decSupplemental,
+}
+
+// decSupplemental is mostly the upper half of Latin-1
+var decSupplemental = func() map[rune]rune {
+	m := map[rune]rune{}
+	for c := rune('!'); c <= '~'; c++ {
+		m[c] = c + 0x80
+	}
+	m['('] = '¤'
+	m['W'] = 'Œ'
+	m[']'] = 'Ÿ'
+	m['w'] = 'œ'
+	m['}'] = 'ÿ'
+	return m
+}()
+
+// translate maps printed characters to the invoked character set, leaving escape codes as is
+func (terminal *Terminal) translate(text string) string {
+	if terminal.singleShift == 0 && charsets[terminal.charsets[terminal.gl]] == nil {
+		return text
+	}
+	var result strings.Builder
+	for text != "" {
+		if text[0] == '\x1b' {
+			if loc := allAnsiCodes.FindStringIndex(text); loc != nil && loc[0] == 0 {
+				result.WriteString(text[:loc[1]])
+				text = text[loc[1]:]
+				continue
+			}
+		}
+		set := terminal.charsets[terminal.gl]
+		if terminal.singleShift != 0 {
+			set = terminal.charsets[terminal.singleShift]
+			terminal.singleShift = 0
+		}
+		r, size := utf8.DecodeRuneInString(text)
+		if mapped, found := charsets[set][r]; found {
+			result.WriteRune(mapped)
+		} else {
+			result.WriteString(text[:size])
+		}
+		text = text[size:]
+	}
+	return result.String()
+}
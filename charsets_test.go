@@ -0,0 +1,37 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineDrawing(t *testing.T) {
+	input := "\x1b(0lqqk\x1b(B\n" +
+		"\x1b(0x\x1b(Bhi\x1b(0x\x1b(B\n" +
+		"\x1b)0\x0emqq\x1b[1mj\x1b[m\x0f"
+
+	assertEqualsStr(t, "┌──┐,│hi│,└──\x1b[1m┘\x1b[m", strings.Join(Capture(strings.NewReader(input)), ","))
+}
+
+func TestCharsets(t *testing.T) {
+	input := "\x1b(A#1\x1b(B #2\n" + // UK
+		"\x1b*0\x1b+<a\x1bNqa\x1bOWa\x1bnx\x1boW\x0f#" // single and locking shifts of G2 and G3
+
+	assertEqualsStr(t, "£1 #2,a─aŒa│Œ#", strings.Join(Capture(strings.NewReader(input)), ","))
+}
+
+func TestCharsetSplitWrite(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte("a\x1b"))
+	terminal.Write([]byte("(0q\x1b("))
+	terminal.Write([]byte("Bq"))
+
+	assertEqualsStr(t, "a─q", strings.Join(terminal.Lines(), ","))
+}
@@ -8,14 +8,180 @@
 package main
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"github.com/tingstad/termscreen"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 )
 
 func main() {
-	lines := termscreen.Capture(os.Stdin)
+	inputFormat := flag.String("input-format", "raw", "input `format`: raw, asciicast, script or ttyrec")
+	format := flag.String("format", "text", "output `format`: text, html, json (lines and links), or svg or gif for an animation of a recording")
+	timing := flag.String("timing", "", "timing `file` of script input")
+	size := flag.String("size", "", "screen size as `COLUMNSxROWS`, instead of recorded or unlimited size")
+	frame := flag.Int("frame", 0, "print screen after event `N` (1 is first) of a recording, instead of the final screen")
+	frames := flag.Bool("frames", false, "print screen after every event of a recording, separated by form feed")
+	record := flag.String("record", "", "also write raw input to asciicast v2 `file`, timed as it is read")
+	idleLimit := flag.Duration("idle-limit", 0, "show no frame of an animation longer than `duration`, e.g. 2s")
+	reflow := flag.Bool("reflow", false, "rewrap wrapped lines when the terminal is resized")
+	joinWrapped := flag.Bool("join-wrapped", false, "print lines which wrapped at the last column as one line")
+	rawLineFeed := flag.Bool("raw-line-feed", false, "make newline only move down, as output of a program in raw mode")
+	explain := flag.Bool("explain", false, "describe each control sequence on stderr, with byte offset and cursor before and after")
+	reportUnsupported := flag.Bool("report-unsupported", false, "list ignored escape sequences on stderr, with count and byte offsets")
+	flag.Parse()
+	formats := map[string]bool{"text": true, "html": true, "json": true, "svg": true, "gif": true}
+	if flag.NArg() > 0 || !formats[*format] || *record != "" && *inputFormat != "raw" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	opts := []termscreen.Option{}
+	if *reflow {
+		opts = append(opts, termscreen.WithReflow())
+	}
+	if *joinWrapped {
+		opts = append(opts, termscreen.JoinWrapped())
+	}
+	if *rawLineFeed {
+		opts = append(opts, termscreen.RawLineFeed())
+	}
+	if *explain {
+		opts = append(opts, termscreen.WithTrace(trace))
+	}
+	if *reportUnsupported {
+		ignored := []termscreen.Sequence{}
+		opts = append(opts, termscreen.WithUnknownHandler(func(seq termscreen.Sequence) {
+			ignored = append(ignored, seq)
+		}))
+		defer func() { report(ignored) }()
+	}
+	var columns, rows int
+	if *size != "" {
+		if _, err := fmt.Sscanf(*size, "%dx%d", &columns, &rows); err != nil {
+			fail(fmt.Errorf("invalid size %q", *size))
+		}
+		opts = append(opts, termscreen.WithSize(columns, rows))
+	}
+	var input io.Reader = os.Stdin
+	if *record != "" {
+		file, err := os.Create(*record)
+		if err != nil {
+			fail(err)
+		}
+		defer file.Close()
+		cast, err := termscreen.NewAsciicastWriter(file, columns, rows)
+		if err != nil {
+			fail(err)
+		}
+		defer cast.Flush()
+		input = io.TeeReader(input, cast)
+	}
+	if *inputFormat == "raw" && *frame == 0 && !*frames && *format != "svg" && *format != "gif" {
+		output(*format, termscreen.Capture(input, opts...))
+		return
+	}
+	recording, err := read(*inputFormat, input, *timing)
+	if err != nil {
+		fail(err)
+	}
+	switch {
+	case *format == "svg":
+		fmt.Print(termscreen.AnimatedSVG(recording.Frames(opts...), *idleLimit))
+	case *format == "gif":
+		if err := termscreen.AnimatedGIF(os.Stdout, recording.Frames(opts...), *idleLimit); err != nil {
+			fail(err)
+		}
+	case *frames:
+		for i, frame := range recording.Frames(opts...) {
+			if i > 0 {
+				fmt.Print("\f\n")
+			}
+			output(*format, frame.Lines)
+		}
+	case *frame != 0:
+		all := recording.Frames(opts...)
+		if *frame < 1 || *frame > len(all) {
+			fail(fmt.Errorf("frame %d not found, there are %d", *frame, len(all)))
+		}
+		output(*format, all[*frame-1].Lines)
+	default:
+		output(*format, recording.Screen(opts...))
+	}
+}
+
+func read(format string, input io.Reader, timing string) (termscreen.Recording, error) {
+	switch format {
+	case "raw":
+		data, err := io.ReadAll(input)
+		return termscreen.Recording{Events: []termscreen.Event{{Data: string(data)}}}, err
+	case "asciicast":
+		return termscreen.ReadAsciicast(input)
+	case "script":
+		if timing == "" {
+			return termscreen.ReadScript(input, nil)
+		}
+		file, err := os.Open(timing)
+		if err != nil {
+			return termscreen.Recording{}, err
+		}
+		defer file.Close()
+		return termscreen.ReadScript(input, file)
+	case "ttyrec":
+		return termscreen.ReadTtyrec(input)
+	}
+	return termscreen.Recording{}, fmt.Errorf("unknown input format %q", format)
+}
+
+func output(format string, lines []string) {
+	switch format {
+	case "html":
+		fmt.Println(termscreen.HTML(lines))
+		return
+	case "json":
+		data, err := json.Marshal(struct {
+			Lines []string          `json:"lines"`
+			Links []termscreen.Link `json:"links"`
+		}{lines, termscreen.Links(lines)})
+		if err != nil {
+			fail(err)
+		}
+		fmt.Printf("%s\n", data)
+		return
+	}
 	for _, line := range lines {
 		fmt.Printf("%s\n", line)
 	}
 }
+
+// trace prints step, with the cursor as row and column (1-based), like in the sequences
+func trace(step termscreen.Step) {
+	ignored := ""
+	if step.Ignored {
+		ignored = " (ignored)"
+	}
+	fmt.Fprintf(os.Stderr, "%8d %-14q %s%s  %d,%d -> %d,%d\n", step.Offset, step.Text, step.Meaning, ignored,
+		step.Before[1]+1, step.Before[0]+1, step.After[1]+1, step.After[0]+1)
+}
+
+// report prints each ignored sequence once, with the number of times and byte offsets it occurred
+func report(ignored []termscreen.Sequence) {
+	offsets := map[string][]string{}
+	order := []string{}
+	for _, seq := range ignored {
+		if offsets[seq.Text] == nil {
+			order = append(order, seq.Text)
+		}
+		offsets[seq.Text] = append(offsets[seq.Text], strconv.Itoa(seq.Offset))
+	}
+	for _, text := range order {
+		fmt.Fprintf(os.Stderr, "%q: %d at %s\n", text, len(offsets[text]), strings.Join(offsets[text], ", "))
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
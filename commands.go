@@ -0,0 +1,112 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CommandBlock is a command run in a shell, found by shell integration marks (OSC 133):
+// A at start of prompt, B at start of command, C at start of output and D (with exit code) at end.
+type CommandBlock struct {
+	Prompt, Command, Output string
+	ExitCode                int // -1 if not reported, e.g. if the command has not finished
+	// Rows, 0-based as in Terminal.Lines, end exclusive
+	Start, End             int // whole block
+	OutputStart, OutputEnd int
+}
+
+// mark is a shell integration mark, at cursor position
+type mark struct {
+	kind byte
+	args string
+	x, y int
+}
+
+// handleMark handles "A", "B", "C" or "D;exit" (OSC 133)
+func (terminal *Terminal) handleMark(arg string) {
+	if arg == "" {
+		return
+	}
+	args := ""
+	if i := strings.IndexByte(arg, ';'); i >= 0 {
+		args = arg[i+1:]
+	}
+	terminal.marks = append(terminal.marks, mark{kind: arg[0], args: args, x: terminal.x, y: terminal.y})
+}
+
+// Commands returns the commands marked by the shell (see CommandBlock), in order
+func (terminal *Terminal) Commands() []CommandBlock {
+	lines := stripStyles(terminal.joined(0))
+	marks := make([]mark, len(terminal.marks))
+	for i, m := range terminal.marks {
+		m.x, m.y = terminal.joinedPosition(m.x, m.y)
+		marks[i] = m
+	}
+	blocks := []CommandBlock{}
+	for i, start := range marks {
+		if start.kind != 'A' {
+			continue
+		}
+		found := map[byte]mark{}
+		for _, m := range marks[i+1:] {
+			if m.kind == 'A' {
+				break
+			}
+			if _, seen := found[m.kind]; !seen {
+				found[m.kind] = m
+			}
+		}
+		cursor := mark{}
+		cursor.x, cursor.y = terminal.joinedPosition(terminal.x, terminal.y)
+		end := func(kinds ...byte) mark {
+			for _, kind := range kinds {
+				if m, ok := found[kind]; ok {
+					return m
+				}
+			}
+			return cursor
+		}
+		block := CommandBlock{ExitCode: -1, Start: start.y}
+		commandStart, outputStart, outputEnd := end('B', 'C', 'D'), end('C', 'D'), end('D')
+		block.Prompt = between(lines, start, commandStart)
+		block.Command = between(lines, commandStart, outputStart)
+		block.Output = between(lines, outputStart, outputEnd)
+		block.OutputStart, block.OutputEnd = outputStart.y, outputEnd.y
+		if outputEnd.x > 0 {
+			block.OutputEnd++
+		}
+		block.End = max(block.OutputEnd, commandStart.y+1)
+		if d, ok := found['D']; ok {
+			code, _, _ := strings.Cut(d.args, ";")
+			if exit, err := strconv.Atoi(code); err == nil {
+				block.ExitCode = exit
+			}
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
+}
+
+// between returns text (without styles) from one position to another, without trailing space
+func between(lines []string, from, to mark) string {
+	text := []string{}
+	for y := from.y; y <= to.y && y < len(lines); y++ {
+		line := []rune(lines[y])
+		begin, end := 0, len(line)
+		if y == from.y {
+			begin = min(from.x, len(line))
+		}
+		if y == to.y {
+			end = min(to.x, len(line))
+		}
+		text = append(text, strings.TrimRight(string(line[begin:max(begin, end)]), " "))
+	}
+	return strings.TrimRight(strings.Join(text, "\n"), "\n")
+}
@@ -0,0 +1,74 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+const session = "\x1b]133;A\x07$ \x1b]133;B\x07ls\r\n\x1b]133;C\x07one\r\ntwo\r\n\x1b]133;D;0\x07" +
+	"\x1b]133;A\x07$ \x1b]133;B\x07false\r\n\x1b]133;C\x07\x1b]133;D;1;aid=42\x07" +
+	"\x1b]133;A;k=s\x07\x1b[32m$\x1b[m \x1b]133;B\x07sleep 9\r\n\x1b]133;C\x07zz"
+
+func TestCommands(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte(session))
+
+	assertEqualsStr(t, "$ ls,one,two,$ false,$ sleep 9,zz", strings.Join(stripStyles(terminal.Lines()), ","))
+	commands := terminal.Commands()
+	assertEquals(t, 3, len(commands))
+	ls := commands[0]
+	assertEqualsStr(t, "$", ls.Prompt)
+	assertEqualsStr(t, "ls", ls.Command)
+	assertEqualsStr(t, "one\ntwo", ls.Output)
+	assertEquals(t, 0, ls.ExitCode)
+	assertEquals(t, 0, ls.Start)
+	assertEquals(t, 3, ls.End)
+	assertEquals(t, 1, ls.OutputStart)
+	assertEquals(t, 3, ls.OutputEnd)
+	failed := commands[1]
+	assertEqualsStr(t, "false", failed.Command)
+	assertEqualsStr(t, "", failed.Output)
+	assertEquals(t, 1, failed.ExitCode)
+	assertEquals(t, 3, failed.Start)
+	assertEquals(t, 4, failed.End)
+	running := commands[2]
+	assertEqualsStr(t, "$", running.Prompt)
+	assertEqualsStr(t, "sleep 9", running.Command)
+	assertEqualsStr(t, "zz", running.Output)
+	assertEquals(t, -1, running.ExitCode)
+	assertEquals(t, 5, running.OutputStart)
+	assertEquals(t, 6, running.OutputEnd)
+}
+
+func TestCommandsCleared(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte(session + "\x1b[2J\x1b]133;A\x07> \x1b]133;B\x07"))
+
+	commands := terminal.Commands()
+	assertEquals(t, 1, len(commands))
+	assertEqualsStr(t, ">", commands[0].Prompt)
+	assertEqualsStr(t, "", commands[0].Command)
+}
+
+func TestCommandsJoinWrapped(t *testing.T) {
+	terminal := NewTerminal(WithSize(5, 10), JoinWrapped())
+	terminal.Write([]byte("\x1b]133;A\x07$ \x1b]133;B\x07ls\r\n\x1b]133;C\x07abcdefgh\r\n" +
+		"\x1b]133;D;0\x07\x1b]133;A\x07$ \x1b]133;B\x07echo long\r\n"))
+
+	assertEqualsStr(t, "$ ls,abcdefgh,$ echo long", strings.Join(stripStyles(terminal.Lines()), ","))
+	commands := terminal.Commands()
+	assertEquals(t, 2, len(commands))
+	assertEqualsStr(t, "abcdefgh", commands[0].Output)
+	assertEquals(t, 1, commands[0].OutputStart)
+	assertEquals(t, 2, commands[0].OutputEnd)
+	assertEquals(t, 2, commands[0].End)
+	assertEquals(t, 2, commands[1].Start)
+	assertEqualsStr(t, "echo long", commands[1].Command)
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"encoding/base64"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Effect is something a program did besides drawing the screen
+type Effect struct {
+	Time      time.Duration // since the terminal was created, or the start of a recording
+	Kind      EffectKind
+	Title     string // of Notification
+	Text      string // of Notification, or copied to Clipboard
+	Selection string // of Clipboard, e.g. "c" (clipboard) or "p" (primary), "" means the default
+}
+
+type EffectKind int
+
+const (
+	Bell         EffectKind = iota // BEL
+	Notification                   // OSC 9 or OSC 777
+	Clipboard                      // OSC 52
+)
+
+func (kind EffectKind) String() string {
+	switch kind {
+	case Bell:
+		return "Bell"
+	case Notification:
+		return "Notification"
+	case Clipboard:
+		return "Clipboard"
+	}
+	return "EffectKind(" + strconv.Itoa(int(kind)) + ")"
+}
+
+// Effects returns bells, notifications and clipboard changes, in order
+func (terminal *Terminal) Effects() []Effect {
+	return terminal.effects
+}
+
+func (terminal *Terminal) effect(effect Effect) {
+	effect.Time = terminal.elapsed()
+	terminal.effects = append(terminal.effects, effect)
+}
+
+// conEmuCommand matches OSC 9 commands of ConEmu (like progress), which are not notifications
+var conEmuCommand = regexp.MustCompile("^[0-9]+(;|$)")
+
+func (terminal *Terminal) notify(title, text string) {
+	if title == "" && conEmuCommand.MatchString(text) {
+		return
+	}
+	terminal.effect(Effect{Kind: Notification, Title: title, Text: text})
+}
+
+// copy handles "selection;base64" (OSC 52). Queries ("?") are not answered.
+func (terminal *Terminal) copy(arg string) {
+	selection, data, found := strings.Cut(arg, ";")
+	if !found || data == "?" {
+		return
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return
+	}
+	terminal.effect(Effect{Kind: Clipboard, Text: string(decoded), Selection: selection})
+}
@@ -0,0 +1,49 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEffects(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte("a\ab\x1b]52;c;aGVsbG8=\x07" + // clipboard "hello"
+		"\x1b]52;c;?\x07" + // query, ignored
+		"\x1b]9;Build done\x1b\\" +
+		"\x1b]9;4;1;50\x07" + // ConEmu progress, ignored
+		"\x1b]777;notify;Tests;All passed\x07c"))
+
+	assertEqualsStr(t, "abc", strings.Join(terminal.Lines(), ","))
+	effects := terminal.Effects()
+	assertEquals(t, 4, len(effects))
+	assertEqualsStr(t, "Bell", effects[0].Kind.String())
+	assertEqualsStr(t, "Clipboard", effects[1].Kind.String())
+	assertEqualsStr(t, "c", effects[1].Selection)
+	assertEqualsStr(t, "hello", effects[1].Text)
+	assertEqualsStr(t, "Notification", effects[2].Kind.String())
+	assertEqualsStr(t, "", effects[2].Title)
+	assertEqualsStr(t, "Build done", effects[2].Text)
+	assertEqualsStr(t, "Tests", effects[3].Title)
+	assertEqualsStr(t, "All passed", effects[3].Text)
+}
+
+func TestEffectsTimed(t *testing.T) {
+	recording := Recording{Events: []Event{
+		{Time: 0, Data: "working"},
+		{Time: 3 * time.Second, Data: "\r\ndone\a"},
+	}}
+	terminal := recording.Replay()
+
+	assertEqualsStr(t, "working,done", strings.Join(terminal.Lines(), ","))
+	assertEquals(t, 1, len(terminal.Effects()))
+	assertTrue(t, terminal.Effects()[0].Kind == Bell)
+	assertTrue(t, terminal.Effects()[0].Time == 3*time.Second)
+}
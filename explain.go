@@ -0,0 +1,237 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Step is a control character or escape sequence handled by the terminal
+type Step struct {
+	Sequence
+	Meaning       string // e.g. "CUP row 3 col 10"
+	Ignored       bool   // not known or not supported
+	Before, After [2]int // cursor column and row on screen, 0-based, like Cursor returns
+}
+
+// WithTrace calls trace after each control character and escape sequence is handled
+func WithTrace(trace func(step Step)) Option {
+	return func(o *opt) {
+		o.trace = trace
+	}
+}
+
+// traceStep reports seq, with the cursor before and after it was handled
+func (terminal *Terminal) traceStep(seq Sequence, ignored bool, before, after [2]int) {
+	if terminal.opt.trace != nil {
+		terminal.opt.trace(Step{Sequence: seq, Meaning: explain(seq), Ignored: ignored,
+			Before: before, After: after})
+	}
+}
+
+// cursorAfter returns the cursor column and row on screen after text is printed, not counting
+// wrapping
+func (terminal *Terminal) cursorAfter(text string) [2]int {
+	x, y := terminal.Cursor()
+	if width := terminal.lineWidth(terminal.y); width > 0 {
+		return [2]int{min(x+length(text), width-1), y}
+	}
+	return [2]int{x + length(text), y}
+}
+
+var controlNames = map[string]string{"\t": "HT tab", "\n": "LF line feed", "\r": "CR carriage return",
+	"\x07": "BEL bell", "\x0b": "VT vertical tab", "\x0c": "FF form feed", "\x0e": "SO shift out",
+	"\x0f": "SI shift in"}
+
+var escapeNames = map[string]string{"7": "DECSC save cursor", "8": "DECRC restore cursor",
+	"D": "IND index", "E": "NEL next line", "M": "RI reverse index", "H": "HTS set tab stop",
+	"#3": "DECDHL double-height top", "#4": "DECDHL double-height bottom", "#5": "DECSWL single-width",
+	"#6": "DECDWL double-width", "#8": "DECALN alignment test", "n": "LS2 G2 to GL", "o": "LS3 G3 to GL",
+	"N": "SS2 single shift G2", "O": "SS3 single shift G3"}
+
+var charsetNames = map[string]string{"B": "ASCII", "0": "DEC graphics", "A": "UK",
+	"<": "DEC supplemental", "%5": "DEC supplemental"}
+
+var csiNames = map[string]string{"A": "CUU up", "B": "CUD down", "C": "CUF forward", "D": "CUB back",
+	"E": "CNL next line", "F": "CPL previous line", "I": "CHT forward tab", "Z": "CBT back tab",
+	"X": "ECH erase characters", "S": "SU scroll up", "T": "SD scroll down", "u": "SCORC restore cursor",
+	"c": "DA primary device attributes", ">c": "DA2 secondary device attributes",
+	"?u": "query keyboard flags", "$x": "DECFRA fill rectangle", "$z": "DECERA erase rectangle",
+	"${": "DECSERA selective erase rectangle", "$v": "DECCRA copy rectangle",
+	"\"q": "DECSCA protection", "t": "XTWINOPS window operation"}
+
+var modeNames = map[Mode]string{ApplicationCursorKeys: "application cursor keys",
+	OriginMode: "origin mode", AutoWrap: "autowrap", CursorVisible: "cursor visible",
+	LeftRightMargins: "left/right margins", MouseClicks: "mouse clicks",
+	MouseHighlight: "mouse highlight", MouseDrag: "mouse drag", MouseMotion: "mouse motion",
+	FocusEvents: "focus events", MouseUTF8: "UTF-8 mouse", MouseSGR: "SGR mouse",
+	BracketedPaste: "bracketed paste", SynchronizedOutput: "synchronized output"}
+
+var oscNames = map[string]string{"0": "set icon name and title", "1": "set icon name",
+	"2": "set title", "7": "working directory", "9": "notification", "777": "notification",
+	"52": "clipboard", "133": "shell integration", "1337": "iTerm2"}
+
+var stringNames = map[string]string{"P": "DCS", "_": "APC", "^": "PM", "X": "SOS"}
+
+var colorNames = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+var attributeNames = map[int]string{0: "reset", 1: "bold", 2: "faint", 3: "italic", 4: "underline",
+	5: "blink", 7: "inverse", 8: "hidden", 9: "strikethrough", 21: "double underline",
+	22: "normal intensity", 23: "not italic", 24: "not underlined", 25: "not blinking",
+	27: "not inverse", 28: "visible", 29: "not strikethrough", 39: "default color",
+	49: "on default color"}
+
+// explain returns a short description of seq, e.g. "CUP row 3 col 10" for "\x1b[3;10H"
+func explain(seq Sequence) string {
+	switch seq.Introducer {
+	case "":
+		if seq.Text[0] != '\x1b' {
+			return controlNames[seq.Text]
+		}
+		if name, found := escapeNames[seq.Intermediate+seq.Final]; found {
+			return name
+		}
+		if i := strings.IndexAny(seq.Intermediate, "()*+"); i == 0 {
+			charset := seq.Intermediate[1:] + seq.Final
+			if name, found := charsetNames[charset]; found {
+				charset = name
+			}
+			return fmt.Sprintf("SCS G%d %s", strings.IndexByte("()*+", seq.Intermediate[0]), charset)
+		}
+		return "ESC " + seq.Intermediate + seq.Final
+	case "[":
+		return explainCSI(seq)
+	case "]":
+		name := oscNames[seq.Params]
+		if seq.Params == "8" {
+			if _, uri, _ := strings.Cut(seq.Data, ";"); uri != "" {
+				return "hyperlink " + uri
+			}
+			return "end hyperlink"
+		} else if name == "" {
+			name = "OSC " + seq.Params
+		}
+		if seq.Data == "" {
+			return name
+		}
+		return name + " " + strconv.Quote(seq.Data)
+	}
+	return stringNames[seq.Introducer] + " " + strconv.Quote(seq.Data)
+}
+
+// explainCSI returns a short description of control sequence seq
+func explainCSI(seq Sequence) string {
+	key := seq.Prefix + seq.Intermediate + seq.Final
+	count := seq.Param(0, 1)
+	switch key {
+	case "A", "B", "C", "D", "E", "F", "I", "Z", "X", "S", "T":
+		return fmt.Sprintf("%s %d", csiNames[key], count)
+	case "G":
+		return fmt.Sprintf("CHA col %d", count)
+	case "H":
+		return fmt.Sprintf("CUP row %d col %d", count, seq.Param(1, 1))
+	case "J", "?J":
+		name := map[string]string{"J": "ED erase", "?J": "DECSED selective erase"}[key]
+		return name + " " + choice(seq.Param(0, 0), "below", "above", "all", "scrollback")
+	case "K", "?K":
+		name := map[string]string{"K": "EL erase", "?K": "DECSEL selective erase"}[key]
+		return name + " " + choice(seq.Param(0, 0), "to end of line", "to start of line", "line")
+	case "g":
+		return "TBC clear " + choice(seq.Param(0, 0), "tab stop", "", "", "all tab stops")
+	case "m":
+		return "SGR " + explainStyle(seq)
+	case "n", "?n":
+		return "DSR " + choice(seq.Param(0, 0), "", "", "", "", "", "status", "cursor position")
+	case "r":
+		return fmt.Sprintf("DECSTBM margins top %d bottom %s", count, seq.paramOr(1, "last"))
+	case "s":
+		if seq.Params == "" {
+			return "SCOSC save cursor"
+		}
+		return fmt.Sprintf("DECSLRM margins left %d right %s", count, seq.paramOr(1, "last"))
+	case "t":
+		if seq.Param(0, 0) == 8 {
+			return fmt.Sprintf("XTWINOPS resize rows %d cols %d", seq.Param(1, 0), seq.Param(2, 0))
+		}
+	case "h", "l", "?h", "?l":
+		names := map[string]string{"h": "SM set", "l": "RM reset", "?h": "DECSET", "?l": "DECRST"}
+		return names[key] + " " + explainModes(seq)
+	case "$p", "?$p":
+		return "DECRQM request mode " + explainModes(seq)
+	}
+	if name, found := csiNames[key]; found {
+		return strings.TrimSpace(name + " " + strings.ReplaceAll(seq.Params, ";", " "))
+	}
+	return "CSI " + seq.Prefix + seq.Params + seq.Intermediate + seq.Final
+}
+
+// paramOr returns parameter #i (0-based), or def if it is missing
+func (seq Sequence) paramOr(i int, def string) string {
+	if n := seq.Param(i, -1); n >= 0 {
+		return strconv.Itoa(n)
+	}
+	return def
+}
+
+// choice returns names[i], or i as text if there is no such name
+func choice(i int, names ...string) string {
+	if i >= 0 && i < len(names) && names[i] != "" {
+		return names[i]
+	}
+	return strconv.Itoa(i)
+}
+
+// explainModes returns the names of the modes set, reset or requested by seq
+func explainModes(seq Sequence) string {
+	names := []string{}
+	for i := range strings.Split(seq.Params, ";") {
+		mode := seq.Param(i, 0)
+		name, found := modeNames[Mode(mode)]
+		if seq.Prefix == "" {
+			name, found = "new line mode", mode == newLineMode
+		}
+		if !found {
+			name = strconv.Itoa(mode)
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// explainStyle returns the attributes and colors set by SGR sequence seq, e.g. "bold red"
+func explainStyle(seq Sequence) string {
+	words := []string{}
+	n := len(strings.Split(seq.Params, ";"))
+	for i := 0; i < n; i++ {
+		p := seq.Param(i, 0)
+		on := ""
+		if p >= 40 && p < 50 || p >= 100 && p < 108 {
+			on = "on "
+		}
+		switch {
+		case (p == 38 || p == 48) && seq.Param(i+1, 0) == 5:
+			words = append(words, fmt.Sprintf("%scolor %d", on, seq.Param(i+2, 0)))
+			i += 2
+		case (p == 38 || p == 48) && seq.Param(i+1, 0) == 2:
+			words = append(words, fmt.Sprintf("%s#%02x%02x%02x", on, seq.Param(i+2, 0),
+				seq.Param(i+3, 0), seq.Param(i+4, 0)))
+			i += 4
+		case p >= 30 && p < 38 || p >= 40 && p < 48:
+			words = append(words, on+colorNames[p%10])
+		case p >= 90 && p < 98 || p >= 100 && p < 108:
+			words = append(words, on+"bright "+colorNames[p%10])
+		case attributeNames[p] != "":
+			words = append(words, attributeNames[p])
+		default:
+			words = append(words, strconv.Itoa(p))
+		}
+	}
+	return strings.Join(words, " ")
+}
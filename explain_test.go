@@ -0,0 +1,60 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	steps := []Step{}
+	trace := WithTrace(func(step Step) { steps = append(steps, step) })
+	input := "ab\x1b[3;10H\x1b[1;31mX\x1b[m\x1b[5i\r\n\x1b]0;hi\x07\x1b(0q"
+	lines := Capture(strings.NewReader(input), trace, WithSize(20, 5))
+
+	result := []string{}
+	for _, step := range steps {
+		result = append(result, fmt.Sprintf("%d %q %s %v %v %v", step.Offset, step.Text, step.Meaning,
+			step.Ignored, step.Before, step.After))
+	}
+	assertEqualsStr(t, strings.Join([]string{
+		`2 "\x1b[3;10H" CUP row 3 col 10 false [2 0] [9 2]`,
+		`9 "\x1b[1;31m" SGR bold red false [9 2] [9 2]`,
+		`17 "\x1b[m" SGR reset false [10 2] [10 2]`,
+		`20 "\x1b[5i" CSI 5i true [10 2] [10 2]`,
+		`24 "\r" CR carriage return false [10 2] [0 2]`,
+		`25 "\n" LF line feed false [0 2] [0 3]`,
+		`26 "\x1b]0;hi\a" set icon name and title "hi" false [0 3] [0 3]`,
+		`33 "\x1b(0" SCS G0 DEC graphics false [0 3] [0 3]`,
+	}, "\n"), strings.Join(result, "\n"))
+	assertEqualsStr(t, strings.Join(Capture(strings.NewReader(input), WithSize(20, 5)), "\n"),
+		strings.Join(lines, "\n"))
+}
+
+func TestExplain(t *testing.T) {
+	meanings := []string{}
+	terminal := NewTerminal(WithTrace(func(step Step) { meanings = append(meanings, step.Meaning) }))
+	terminal.Write([]byte("\x1b[2A\x1b[J\x1b[2K\x1b[?25;2004l\x1b[4;1;38;5;100;48;2;255;0;16;94m" +
+		"\x1b]8;;http://example.com\x1b\\\x1b]8;;\x1b\\\x1bM\x1b#6\x1b_note\x1b\\\x1b[5;20r"))
+
+	assertEqualsStr(t, strings.Join([]string{
+		"CUU up 2",
+		"ED erase below",
+		"EL erase line",
+		"DECRST cursor visible, bracketed paste",
+		"SGR underline bold color 100 on #ff0010 bright blue",
+		"hyperlink http://example.com",
+		"end hyperlink",
+		"RI reverse index",
+		"DECDWL double-width",
+		`APC "note"`,
+		"DECSTBM margins top 5 bottom 20",
+	}, "\n"), strings.Join(meanings, "\n"))
+}
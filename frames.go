@@ -0,0 +1,139 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"time"
+)
+
+// Event is output written at a point in time (since the recording started). A resize of the
+// terminal is the control sequence "\x1b[8;rows;columns t".
+type Event struct {
+	Time time.Duration
+	Data string
+}
+
+// Recording is timed terminal output, e.g. from an asciicast file.
+// A plain stream without timing can be used as a single event.
+type Recording struct {
+	Width, Height int // as recorded, or 0 if unknown
+	Events        []Event
+}
+
+// Frame is a snapshot of the screen
+type Frame struct {
+	Time  time.Duration
+	Lines []string
+}
+
+// Screen returns the screen at the end of the recording
+func (recording Recording) Screen(opts ...Option) []string {
+	return recording.Replay(opts...).Screen()
+}
+
+// Replay returns a Terminal after all events, with effects timed as recorded
+func (recording Recording) Replay(opts ...Option) *Terminal {
+	terminal := recording.terminal(opts...)
+	var now time.Duration
+	terminal.elapsed = func() time.Duration { return now }
+	for _, event := range recording.Events {
+		now = event.Time
+		terminal.Write([]byte(event.Data))
+	}
+	return terminal
+}
+
+// ScreenAt returns the screen at time t
+func (recording Recording) ScreenAt(t time.Duration, opts ...Option) []string {
+	terminal := recording.terminal(opts...)
+	for _, event := range recording.Events {
+		if event.Time > t {
+			break
+		}
+		terminal.Write([]byte(event.Data))
+	}
+	return terminal.Screen()
+}
+
+// terminal returns a Terminal with the recorded size, unless overridden by opts
+func (recording Recording) terminal(opts ...Option) *Terminal {
+	return NewTerminal(append([]Option{WithSize(recording.Width, recording.Height)}, opts...)...)
+}
+
+// Frames returns the screen after each event
+func (recording Recording) Frames(opts ...Option) []Frame {
+	terminal := recording.terminal(opts...)
+	frames := []Frame{}
+	for _, event := range recording.Events {
+		terminal.Write([]byte(event.Data))
+		frames = append(frames, Frame{Time: event.Time, Lines: terminal.Screen()})
+	}
+	return frames
+}
+
+// FramesEvery returns the screen at every interval, starting at time 0, until the last event
+func (recording Recording) FramesEvery(interval time.Duration, opts ...Option) []Frame {
+	if interval <= 0 {
+		panic("Interval must be positive")
+	}
+	terminal := recording.terminal(opts...)
+	frames := []Frame{}
+	next := time.Duration(0)
+	for _, event := range recording.Events {
+		for event.Time > next {
+			frames = append(frames, Frame{Time: next, Lines: terminal.Screen()})
+			next += interval
+		}
+		terminal.Write([]byte(event.Data))
+	}
+	return append(frames, Frame{Time: next, Lines: terminal.Screen()})
+}
+
+// FramesAtClear returns the screen every time it is complete: before it is cleared, before and
+// after a synchronized update and at the end of the recording. This splits a full-screen program
+// into the screens it has shown. Blank screens and repeated screens are skipped.
+func (recording Recording) FramesAtClear(opts ...Option) []Frame {
+	terminal := recording.terminal(opts...)
+	frames := []Frame{}
+	now := time.Duration(0)
+	terminal.onFrame = func() {
+		lines := terminal.Screen()
+		if blank(lines) || len(frames) > 0 && equal(lines, frames[len(frames)-1].Lines) {
+			return
+		}
+		frames = append(frames, Frame{Time: now, Lines: lines})
+	}
+	for _, event := range recording.Events {
+		now = event.Time
+		terminal.Write([]byte(event.Data))
+	}
+	terminal.onFrame()
+	return frames
+}
+
+func blank(lines []string) bool {
+	for _, line := range lines {
+		if strings.TrimSpace(stripStyles([]string{line})[0]) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrames(t *testing.T) {
+	recording := Recording{Events: []Event{
+		{Time: 0, Data: "one"},
+		{Time: time.Second, Data: "\x1b[3Dtwo"},
+	}}
+	frames := recording.Frames()
+
+	assertEquals(t, 2, len(frames))
+	assertEqualsStr(t, "one", strings.Join(frames[0].Lines, ","))
+	assertEqualsStr(t, "two", strings.Join(frames[1].Lines, ","))
+	assertEquals(t, int(time.Second), int(frames[1].Time))
+}
+
+func TestFramesEvery(t *testing.T) {
+	recording := Recording{Events: []Event{
+		{Time: 0, Data: "a"},
+		{Time: 1500 * time.Millisecond, Data: "b"},
+		{Time: 2 * time.Second, Data: "c"},
+	}}
+	frames := recording.FramesEvery(time.Second)
+
+	got := []string{}
+	for _, frame := range frames {
+		got = append(got, frame.Time.String()+"="+strings.Join(frame.Lines, ","))
+	}
+	assertEqualsStr(t, "0s=a 1s=a 2s=abc", strings.Join(got, " "))
+}
+
+func TestFramesAtClear(t *testing.T) {
+	recording := Recording{Events: []Event{
+		{Time: 0, Data: "\x1b[2Jfirst\n"},
+		{Time: time.Second, Data: "screen\x1b[H\x1b[2Jsecond"},
+		{Time: 2 * time.Second, Data: "\x1b[?2026h\x1b[2Jthird\x1b[?2026l\x1b[?2026h\x1b[2Jlast"},
+	}}
+	frames := recording.FramesAtClear()
+
+	got := []string{}
+	for _, frame := range frames {
+		got = append(got, frame.Time.String()+"="+strings.Join(frame.Lines, ","))
+	}
+	assertEqualsStr(t, "1s=first,screen 2s=second 2s=third 2s=last", strings.Join(got, " "))
+}
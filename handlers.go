@@ -0,0 +1,114 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Sequence is an escape sequence in the output, e.g. "\x1b[?25h" has introducer "[", prefix "?",
+// params "25" and final "h". An OSC string like "\x1b]1337;CurrentDir=/\x07" has introducer "]",
+// params "1337" and data "CurrentDir=/". Other strings (DCS, APC and so on) only have data.
+type Sequence struct {
+	Offset       int    // number of bytes of output before the sequence, counting all writes (also by handlers)
+	Text         string // the whole sequence, including ESC
+	Introducer   string // "[" for CSI, "]" for OSC, "P" for DCS, "_" for APC, "^" for PM, "X" for SOS, or ""
+	Prefix       string
+	Params       string
+	Intermediate string
+	Final        string
+	Data         string
+}
+
+// Param returns parameter #i (0-based), or def if it is missing
+func (seq Sequence) Param(i int, def int) int {
+	return sequence{params: seq.Params}.param(i, def)
+}
+
+// Handler handles a custom escape sequence, instead of the terminal. It may inspect the terminal
+// and change it, e.g. by writing text to it.
+type Handler func(terminal *Terminal, seq Sequence)
+
+// WithCSIHandler handles control sequences with the given private marker, intermediate bytes and
+// final byte, e.g. "x" for "\x1b[1;2x" or "?$p" for "\x1b[?1$p". Other forms are not handled,
+// e.g. "x" does not match "\x1b[?1x".
+func WithCSIHandler(sequence string, handler Handler) Option {
+	return withHandler("["+sequence, handler)
+}
+
+// WithOSCHandler handles OSC strings with number, e.g. 1337 for "\x1b]1337;data\x07"
+func WithOSCHandler(number int, handler Handler) Option {
+	return withHandler("]"+strconv.Itoa(number), handler)
+}
+
+// WithDCSHandler handles DCS strings with data starting with prefix, e.g. "+q" for "\x1bP+q\x1b\\"
+func WithDCSHandler(prefix string, handler Handler) Option {
+	return withHandler("P"+prefix, handler)
+}
+
+// WithAPCHandler handles APC strings with data starting with prefix, e.g. "G" for "\x1b_Gdata\x1b\\"
+func WithAPCHandler(prefix string, handler Handler) Option {
+	return withHandler("_"+prefix, handler)
+}
+
+func withHandler(key string, handler Handler) Option {
+	return func(o *opt) {
+		if o.handlers == nil {
+			o.handlers = map[string]Handler{}
+		}
+		o.handlers[key] = handler
+	}
+}
+
+// handler returns the custom handler of seq, or nil
+func (terminal *Terminal) handler(seq Sequence) Handler {
+	switch seq.Introducer {
+	case "[":
+		return terminal.opt.handlers["["+seq.Prefix+seq.Intermediate+seq.Final]
+	case "]":
+		return terminal.opt.handlers["]"+seq.Params]
+	case "P", "_":
+		var found Handler
+		longest := -1
+		for key, handler := range terminal.opt.handlers {
+			prefix, ok := strings.CutPrefix(key, seq.Introducer)
+			if ok && len(prefix) > longest && strings.HasPrefix(seq.Data, prefix) {
+				found, longest = handler, len(prefix)
+			}
+		}
+		return found
+	}
+	return nil
+}
+
+// parseSequence returns the sequence matched by ansiControlCodes at indices of text, which starts
+// at offset
+func parseSequence(text string, indices []int, offset int) Sequence {
+	group := func(i int) string {
+		if indices[2*i] < 0 {
+			return ""
+		}
+		return text[indices[2*i]:indices[2*i+1]]
+	}
+	seq := Sequence{Offset: offset + indices[0], Text: group(0)}
+	if seq.Text[0] != '\x1b' { // Control character
+		return seq
+	} else if indices[16] >= 0 { // Escape sequence, not CSI or string
+		seq.Intermediate, seq.Final = group(7), group(8)
+	} else if indices[10] >= 0 { // String
+		seq.Introducer, seq.Data = group(5), group(6)
+		if seq.Introducer == "]" {
+			seq.Params, seq.Data, _ = strings.Cut(seq.Data, ";")
+		}
+	} else {
+		seq.Introducer = "["
+		seq.Prefix, seq.Params, seq.Intermediate, seq.Final = group(1), group(2), group(3), group(4)
+	}
+	return seq
+}
@@ -0,0 +1,112 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCSIHandler(t *testing.T) {
+	handler := func(terminal *Terminal, seq Sequence) {
+		x, y := terminal.Cursor()
+		terminal.Write([]byte(fmt.Sprintf("<%d %d %d,%d>", seq.Param(0, 0), seq.Param(1, 7), x, y)))
+	}
+	lines := Capture(strings.NewReader("ab\x1b[3x\ncd\x1b[2;5x"), WithCSIHandler("x", handler))
+
+	assertEquals(t, 2, len(lines))
+	assertEqualsStr(t, "ab<3 7 2,0>", lines[0])
+	assertEqualsStr(t, "cd<2 5 2,1>", lines[1])
+}
+
+func TestCSIHandlerOverridesBuiltIn(t *testing.T) {
+	finals := []string{}
+	handler := func(terminal *Terminal, seq Sequence) {
+		finals = append(finals, seq.Prefix+seq.Params+seq.Final)
+	}
+	terminal := NewTerminal(WithCSIHandler("?h", handler), WithCSIHandler("h", handler))
+	terminal.Write([]byte("\x1b[?2004habc\x1b[4h"))
+
+	assertEqualsStr(t, "[?2004h 4h]", fmt.Sprint(finals))
+	assertTrue(t, !terminal.PrivateMode(BracketedPaste))
+	assertEqualsStr(t, "abc", terminal.Lines()[0])
+}
+
+func TestOSCHandler(t *testing.T) {
+	steps := []string{}
+	handler := func(terminal *Terminal, seq Sequence) {
+		steps = append(steps, seq.Data)
+	}
+	terminal := NewTerminal(WithOSCHandler(7777, handler))
+	terminal.Write([]byte("\x1b]7777;build\x07a\x1b]77;x\x07\x1b]7777;test\x1b\\b\x1b]0;title\x07"))
+
+	assertEqualsStr(t, "[build test]", fmt.Sprint(steps))
+	assertEqualsStr(t, "ab", terminal.Lines()[0])
+	assertEqualsStr(t, "title", terminal.Title())
+}
+
+func TestAPCAndDCSHandlers(t *testing.T) {
+	calls := []string{}
+	record := func(name string) Handler {
+		return func(terminal *Terminal, seq Sequence) {
+			calls = append(calls, name+":"+seq.Data)
+		}
+	}
+	terminal := NewTerminal(WithAPCHandler("note", record("note")), WithAPCHandler("no", record("no")),
+		WithDCSHandler("+q", record("dcs")))
+	terminal.Write([]byte("\x1b_note=hi\x1b\\\x1b_nope\x1b\\\x1b_x\x1b\\\x1bP+q544e\x1b\\\x1bPq\x1b\\"))
+
+	assertEqualsStr(t, "[note:note=hi no:nope dcs:+q544e]", fmt.Sprint(calls))
+}
+
+func TestHandlerMovesCursor(t *testing.T) {
+	handler := func(terminal *Terminal, seq Sequence) {
+		terminal.Write([]byte("\x1b[H*"))
+	}
+	lines := Capture(strings.NewReader("abc\ndef\x1b_home\x1b\\g"), WithAPCHandler("home", handler))
+
+	assertEqualsStr(t, "*gc", lines[0])
+	assertEqualsStr(t, "def", lines[1])
+}
+
+func TestHandlerWritesAcrossWrites(t *testing.T) {
+	handler := func(terminal *Terminal, seq Sequence) {
+		terminal.Write([]byte("*"))
+	}
+	terminal := NewTerminal(WithCSIHandler("x", handler))
+	terminal.Write([]byte("ab\x1b["))
+	terminal.Write([]byte("1xcd\xe2"))
+	terminal.Write([]byte("\x94\x80\x1b[1x"))
+
+	assertEqualsStr(t, "ab*cd─*", terminal.Lines()[0])
+}
+
+func TestHandlerWriteOffsets(t *testing.T) {
+	offsets := []int{}
+	handler := func(terminal *Terminal, seq Sequence) {
+		offsets = append(offsets, seq.Offset)
+		terminal.Write([]byte("**"))
+	}
+	terminal := NewTerminal(WithCSIHandler("x", handler))
+	terminal.Write([]byte("ab\x1b["))
+	terminal.Write([]byte("1xc\x1b[x"))
+
+	assertEqualsStr(t, "[2 9]", fmt.Sprint(offsets))
+}
+
+func TestCSIHandlerKeepsOtherForms(t *testing.T) {
+	var responses strings.Builder
+	calls := 0
+	handler := func(terminal *Terminal, seq Sequence) { calls++ }
+	terminal := NewTerminal(WithCSIHandler("p", handler), WithResponseWriter(&responses))
+	terminal.Write([]byte("\x1b[1p\x1b[?2004h\x1b[?2004$p\x1b[20$p\x1b[!p"))
+
+	assertEquals(t, 1, calls)
+	assertEqualsStr(t, "\x1b[?2004;1$y\x1b[20;2$y", responses.String())
+}
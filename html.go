@@ -0,0 +1,114 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// HTML renders lines (as returned by Capture) as a <pre> element, with styles inline. Hyperlinks
+// are only kept if their scheme is http, https, file or mailto.
+func HTML(lines []string) string {
+	var b strings.Builder
+	b.WriteString(`<pre style="color:` + defaultForeground.hex(defaultForeground) + `;background-color:` + defaultBackground.hex(defaultBackground) + `">`)
+	for i, line := range lines {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		size := lineSize(line)
+		if size == doubleHeightTop || size == doubleHeightBottom { // show half
+			b.WriteString(`<span style="display:inline-block;height:1lh;overflow:hidden;vertical-align:top">`)
+		}
+		if size != 0 {
+			b.WriteString(`<span style="display:inline-block;` + lineSizeCSS[size] + `">`)
+		}
+		uri := ""
+		for _, run := range runs(line) {
+			if !safeLink(run.uri) { // e.g. javascript:, which would run in the page
+				run.uri = ""
+			}
+			if run.uri != uri {
+				if uri != "" {
+					b.WriteString("</a>")
+				}
+				if run.uri != "" {
+					b.WriteString(`<a href="` + html.EscapeString(run.uri) + `">`)
+				}
+				uri = run.uri
+			}
+			text := html.EscapeString(run.text)
+			if css := run.attr.css(); css != "" {
+				text = `<span style="` + css + `">` + text + `</span>`
+			}
+			b.WriteString(text)
+		}
+		if uri != "" {
+			b.WriteString("</a>")
+		}
+		if size != 0 {
+			b.WriteString("</span>")
+		}
+		if size == doubleHeightTop || size == doubleHeightBottom {
+			b.WriteString("</span>")
+		}
+	}
+	b.WriteString("</pre>")
+	return b.String()
+}
+
+// safeSchemes are the schemes of URIs kept as links in HTML
+var safeSchemes = map[string]bool{"http": true, "https": true, "file": true, "mailto": true}
+
+// safeLink returns whether uri has a safe scheme
+func safeLink(uri string) bool {
+	location, err := url.Parse(uri)
+	return err == nil && safeSchemes[location.Scheme]
+}
+
+// lineSizeCSS scales double-size lines
+var lineSizeCSS = map[byte]string{
+	doubleWidth:        "transform:scaleX(2);transform-origin:0 0",
+	doubleHeightTop:    "transform:scale(2);transform-origin:0 0",
+	doubleHeightBottom: "transform:scale(2);transform-origin:0 100%",
+}
+
+func (attr attributes) css() string {
+	styles := []string{}
+	fg, bg := attr.colors()
+	if fg != defaultColor {
+		styles = append(styles, "color:"+fg.hex(defaultForeground))
+	}
+	if bg != defaultColor {
+		styles = append(styles, "background-color:"+bg.hex(defaultBackground))
+	}
+	if attr.bold {
+		styles = append(styles, "font-weight:bold")
+	}
+	if attr.faint {
+		styles = append(styles, "opacity:0.5")
+	}
+	if attr.italic {
+		styles = append(styles, "font-style:italic")
+	}
+	decorations := []string{}
+	if attr.underline {
+		decorations = append(decorations, "underline")
+	}
+	if attr.strike {
+		decorations = append(decorations, "line-through")
+	}
+	if len(decorations) > 0 {
+		styles = append(styles, "text-decoration:"+strings.Join(decorations, " "))
+	}
+	if attr.hidden {
+		styles = append(styles, "visibility:hidden")
+	}
+	return strings.Join(styles, ";")
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"testing"
+)
+
+func ExampleHTML() {
+	fmt.Println(HTML(Capture(strings.NewReader("<\x1b[1;31mred\x1b[m>\nline 2"))))
+
+	// Output:
+	// <pre style="color:#e5e5e5;background-color:#000000">&lt;<span style="color:#cd0000;font-weight:bold">red</span>&gt;
+	// line 2</pre>
+}
+
+func TestHTMLInverse(t *testing.T) {
+	got := HTML([]string{"\x1b[7;32mok"})
+
+	assertTrue(t, strings.Contains(got, `<span style="color:#000000;background-color:#00cd00">ok</span>`))
+}
+
+func TestHTMLUnsafeLink(t *testing.T) {
+	for _, uri := range []string{"javascript:alert(1)", "JavaScript:alert(1)", " javascript:alert(1)",
+		"data:text/html,<script>", "java\tscript:alert(1)"} {
+		got := HTML(Capture(strings.NewReader("\x1b]8;;" + uri + "\x1b\\click\x1b]8;;\x1b\\")))
+
+		assertTrue(t, !strings.Contains(got, "<a "))
+		assertTrue(t, strings.Contains(got, ">click</pre>"))
+	}
+}
+
+func TestHTMLLink(t *testing.T) {
+	for _, uri := range []string{"https://example.com/?a=1&b=2", "file:///tmp/x", "mailto:me@example.com"} {
+		got := HTML(Capture(strings.NewReader("\x1b]8;;" + uri + "\x1b\\click\x1b]8;;\x1b\\")))
+
+		assertTrue(t, strings.Contains(got, `<a href="`+html.EscapeString(uri)+`">click</a>`))
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import "strings"
+
+// Line sizes, set by "\x1b#3" and so on. Lines returned by Terminal and Capture begin with the
+// code if they are not single-width.
+const (
+	doubleHeightTop    = '3'
+	doubleHeightBottom = '4'
+	singleWidth        = '5'
+	doubleWidth        = '6'
+)
+
+// lineSize returns the size of line: doubleHeightTop, doubleHeightBottom, doubleWidth, or 0
+func lineSize(line string) byte {
+	if len(line) >= 3 && line[:2] == "\x1b#" && line[2] != singleWidth {
+		return line[2]
+	}
+	return 0
+}
+
+// setLineSize handles DECDHL, DECSWL and DECDWL for the cursor row. Characters past the half of
+// the screen are lost when the row becomes double-width.
+func (terminal *Terminal) setLineSize(size byte) {
+	y := terminal.y
+	if size == singleWidth {
+		delete(terminal.lineSizes, y)
+		return
+	}
+	if terminal.lineSizes == nil {
+		terminal.lineSizes = map[int]byte{}
+	}
+	terminal.lineSizes[y] = size
+	if half := terminal.opt.width / 2; half > 0 {
+		if y < len(terminal.screen) && length(terminal.screen[y]) > half {
+			terminal.screen[y] = terminal.screen[y][:pos(terminal.screen[y], half)]
+		}
+		terminal.x = min(terminal.x, half-1)
+	}
+}
+
+// lineWidth returns the number of columns of row y (0 means unlimited)
+func (terminal *Terminal) lineWidth(y int) int {
+	if terminal.lineSizes[y] != 0 {
+		return terminal.opt.width / 2
+	}
+	return terminal.opt.width
+}
+
+// sized returns the rows starting at from, with the code of their line size
+func (terminal *Terminal) sized(from int) []string {
+	lines := append([]string{}, terminal.screen[from:]...)
+	for y, size := range terminal.lineSizes {
+		if y >= from && y < len(terminal.screen) {
+			lines[y-from] = "\x1b#" + string(size) + lines[y-from]
+		}
+	}
+	return lines
+}
+
+// alignmentTest handles DECALN ("\x1b#8"), filling the screen with E's
+func (terminal *Terminal) alignmentTest() {
+	columns, rows := terminal.size()
+	terminal.margins = margins{}
+	terminal.clearRows(terminal.top, terminal.top+rows)
+	for len(terminal.screen) < terminal.top+rows {
+		terminal.screen = append(terminal.screen, "")
+	}
+	for y := terminal.top; y < terminal.top+rows; y++ {
+		terminal.screen[y] = strings.Repeat("E", columns)
+	}
+	terminal.x, terminal.y = 0, terminal.top
+}
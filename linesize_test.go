@@ -0,0 +1,58 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLineSize(t *testing.T) {
+	input := "\x1b#3Banner\n\x1b#4Banner\n\x1b#6wide\x1b#5\nnormal"
+	lines := Capture(strings.NewReader(input))
+
+	assertEqualsStr(t, "\x1b#3Banner,\x1b#4Banner,wide,normal", strings.Join(lines, ","))
+	assertEqualsStr(t, "Banner,Banner,wide,normal", strings.Join(Capture(strings.NewReader(input), StripStyling()), ","))
+}
+
+func TestDoubleWidthColumns(t *testing.T) {
+	terminal := NewTerminal(WithSize(10, 3))
+	terminal.Write([]byte("0123456789\r\n\x1b#6abcdefg\x1b[1;8H\x1b#6\x1b[2;9Hx"))
+
+	assertEqualsStr(t, "\x1b#601234,\x1b#6abcdx,fg", strings.Join(terminal.Lines(), ","))
+}
+
+func TestAlignmentTest(t *testing.T) {
+	terminal := NewTerminal(WithSize(4, 2))
+	terminal.Write([]byte("\x1b#6ab\x1b#8x"))
+
+	assertEqualsStr(t, "xEEE,EEEE", strings.Join(terminal.Lines(), ","))
+}
+
+func TestLineSizeScroll(t *testing.T) {
+	terminal := NewTerminal(WithSize(10, 3))
+	terminal.Write([]byte("\x1b#6one\r\ntwo\r\nthree\r\n\x1b#6four"))
+
+	assertEqualsStr(t, "\x1b#6one,two,three,\x1b#6four", strings.Join(terminal.Lines(), ","))
+	assertEqualsStr(t, "two,three,\x1b#6four", strings.Join(terminal.Screen(), ","))
+
+	terminal.Write([]byte("\x1b[2;3r\x1b[S"))
+	assertEqualsStr(t, "two,\x1b#6four,", strings.Join(terminal.Screen(), ","))
+}
+
+func TestLineSizeRendering(t *testing.T) {
+	lines := []string{"\x1b#6wide", "\x1b#3tall", "\x1b#4tall"}
+
+	html := HTML(lines)
+	assertTrue(t, strings.Contains(html, `<span style="display:inline-block;transform:scaleX(2);transform-origin:0 0">wide</span>`))
+	assertTrue(t, strings.Contains(html, `transform-origin:0 100%">tall</span></span>`))
+	svg := AnimatedSVG([]Frame{{Lines: lines}}, time.Second)
+	assertTrue(t, strings.Contains(svg, `<g transform="scale(2 1)">`))
+	assertTrue(t, strings.Contains(svg, `<svg y="17" height="17" overflow="hidden"><g transform="translate(0 0) scale(2)">`))
+}
@@ -0,0 +1,128 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// ansiLinkCodes matches hyperlinks (OSC 8), "\x1b]8;params;uri\x1b\\", ended by an empty uri
+var ansiLinkCodes = regexp.MustCompile("\x1b\\]8;([^;\x07\x1b]*);([^\x07\x1b]*)\x1b\\\\")
+
+const linkEnd = "\x1b]8;;\x1b\\"
+
+// Link is a hyperlink on screen. A link broken over several lines is one Link per line.
+type Link struct {
+	Row    int    `json:"row"`    // 0-based
+	Column int    `json:"column"` // 0-based, of first character
+	Text   string `json:"text"`
+	URI    string `json:"uri"`
+	ID     string `json:"id,omitempty"` // optional, links with the same ID belong together
+}
+
+// Links returns the hyperlinks in lines (as returned by Capture)
+func Links(lines []string) []Link {
+	links := []Link{}
+	for row, line := range lines {
+		column := 0
+		var link *Link
+		for _, run := range runs(line) {
+			if link != nil && (run.uri != link.URI || run.linkID != link.ID) {
+				links = append(links, *link)
+				link = nil
+			}
+			if link == nil && run.uri != "" {
+				link = &Link{Row: row, Column: column, URI: run.uri, ID: run.linkID}
+			}
+			if link != nil {
+				link.Text += run.text
+			}
+			column += utf8.RuneCountInString(run.text)
+		}
+		if link != nil {
+			links = append(links, *link)
+		}
+	}
+	return links
+}
+
+// parseLink returns id and uri of a hyperlink code
+func parseLink(code string) (string, string) {
+	match := ansiLinkCodes.FindStringSubmatch(code)
+	if match == nil {
+		return "", ""
+	}
+	id := ""
+	for _, param := range strings.Split(match[1], ":") {
+		if value, found := strings.CutPrefix(param, "id="); found {
+			id = value
+		}
+	}
+	return id, match[2]
+}
+
+// openLink returns the hyperlink code in effect at the end of value, or "" if none
+func openLink(value string) string {
+	codes := ansiLinkCodes.FindAllStringSubmatch(value, -1)
+	if len(codes) == 0 || codes[len(codes)-1][2] == "" {
+		return ""
+	}
+	return codes[len(codes)-1][0]
+}
+
+// cleanLinks removes hyperlink codes that are redundant, like ending a link and starting it again,
+// and ends a link left open at the end of the line
+func cleanLinks(line string) string {
+	if !strings.Contains(line, "\x1b]8;") {
+		return line
+	}
+	result := ""
+	open := ""
+	for {
+		loc := ansiLinkCodes.FindStringIndex(line)
+		if loc == nil {
+			break
+		}
+		code := line[loc[0]:loc[1]]
+		result += line[:loc[0]]
+		line = line[loc[1]:]
+		if code == linkEnd {
+			if open == "" {
+				continue
+			}
+			// skip style codes, to see if the same link starts again
+			rest, styles := line, ""
+			for {
+				next := allAnsiCodes.FindStringIndex(rest)
+				if next == nil || next[0] != 0 || ansiLinkCodes.MatchString(rest[:next[1]]) {
+					break
+				}
+				styles += rest[:next[1]]
+				rest = rest[next[1]:]
+			}
+			if strings.HasPrefix(rest, open) {
+				result += styles
+				line = rest[len(open):]
+				continue
+			}
+			open = ""
+		} else if code == open {
+			continue
+		} else {
+			open = code
+		}
+		result += code
+	}
+	result += line
+	if open != "" {
+		result += linkEnd
+	}
+	return result
+}
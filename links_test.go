@@ -0,0 +1,72 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+const link = "\x1b]8;;http://example.com\x1b\\"
+
+func TestLinkCapture(t *testing.T) {
+	lines := captureStringReader(strReader("see " + link + "example\x1b]8;;\x07 here\n"))
+
+	assertEqualsStr(t, "see "+link+"example"+linkEnd+" here", lines[0])
+	assertEqualsStr(t, "see example here", stripStyles(lines)[0])
+}
+
+func TestLinkLength(t *testing.T) {
+	assertEquals(t, 7, length(link+"example"+linkEnd))
+	assertEquals(t, 4+len(link), pos(link+"example"+linkEnd, 4))
+}
+
+func TestLinkOverwrite(t *testing.T) {
+	lines := captureStringReader(strReader(link + "example" + linkEnd + "\x1b[1;3HX"))
+
+	assertEqualsStr(t, link+"ex"+linkEnd+"X"+link+"mple"+linkEnd, lines[0])
+}
+
+func TestLinkStyled(t *testing.T) {
+	lines := captureStringReader(strReader("\x1b[1m" + link + "bold\x1b[m plain" + linkEnd))
+
+	assertEqualsStr(t, "\x1b[1m"+link+"bold\x1b[m plain"+linkEnd, lines[0])
+}
+
+func TestLinkWrapped(t *testing.T) {
+	lines := captureStringReader(strReader("> "+link+"example"+linkEnd+" <"), WithSize(5, 0))
+
+	assertEqualsStr(t, "> "+link+"exa"+linkEnd+","+link+"mple"+linkEnd+" ,<", strings.Join(lines, ","))
+}
+
+func TestCleanLinks(t *testing.T) {
+	assertEqualsStr(t, "a"+link+"bc"+linkEnd, cleanLinks("a"+link+"b"+linkEnd+link+"c"))
+	assertEqualsStr(t, link+"b\x1b[1mc"+linkEnd, cleanLinks(link+"b"+linkEnd+"\x1b[1m"+link+"c"+linkEnd))
+	assertEqualsStr(t, "ab", cleanLinks("a"+linkEnd+"b"))
+}
+
+func TestLinks(t *testing.T) {
+	other := "\x1b]8;id=x:y=z;file:///tmp\x1b\\"
+	links := Links([]string{"no links", "a " + link + "b\x1b[1mc" + linkEnd + other + "d" + linkEnd})
+
+	assertEquals(t, 2, len(links))
+	assertEquals(t, 1, links[0].Row)
+	assertEquals(t, 2, links[0].Column)
+	assertEqualsStr(t, "bc", links[0].Text)
+	assertEqualsStr(t, "http://example.com", links[0].URI)
+	assertEqualsStr(t, "", links[0].ID)
+	assertEquals(t, 4, links[1].Column)
+	assertEqualsStr(t, "x", links[1].ID)
+	assertEqualsStr(t, "file:///tmp", links[1].URI)
+}
+
+func TestLinkHTML(t *testing.T) {
+	got := HTML([]string{"a " + link + "b\x1b[1mc&" + linkEnd + " d"})
+
+	assertTrue(t, strings.Contains(got, `>a <a href="http://example.com">b<span style="font-weight:bold">c&amp;</span></a><span style="font-weight:bold"> d</span></pre>`))
+}
@@ -0,0 +1,181 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import "strings"
+
+// LeftRightMargins is the DEC private mode which allows setting left and right margins
+const LeftRightMargins Mode = 69
+
+// margins limit scrolling (and, in origin mode, the cursor) to part of the screen.
+// They are only used with a fixed size (WithSize). Rows and columns are 0-based, relative to the
+// screen, and 0 as bottom or right means the last row or column.
+type margins struct {
+	top, bottom, left, right int
+}
+
+// cursor is saved by DECSC ("\x1b7") and restored by DECRC ("\x1b8")
+type cursor struct {
+	x, y     int // y is relative to the screen
+	style    string
+	origin   bool
+	charsets [4]string
+	gl       int
+}
+
+// hasMargins returns whether the scroll region is smaller than the screen
+func (terminal *Terminal) hasMargins() bool {
+	return terminal.margins != margins{}
+}
+
+// region returns the first and last row (absolute) and column of the scroll region
+func (terminal *Terminal) region() (top, bottom, left, right int) {
+	top, bottom = terminal.top+terminal.margins.top, terminal.top+terminal.opt.height-1
+	if terminal.margins.bottom > 0 {
+		bottom = terminal.top + terminal.margins.bottom
+	}
+	left, right = terminal.margins.left, terminal.opt.width-1
+	if terminal.margins.right > 0 {
+		right = terminal.margins.right
+	}
+	return top, bottom, left, right
+}
+
+// home returns the first position of the screen, or of the scroll region in origin mode
+func (terminal *Terminal) home() (int, int) {
+	if terminal.modes[OriginMode] {
+		top, _, left, _ := terminal.region()
+		return left, top
+	}
+	return 0, terminal.top
+}
+
+// setVerticalMargins handles DECSTBM ("\x1b[top;bottom r")
+func (terminal *Terminal) setVerticalMargins(seq sequence) {
+	height := terminal.opt.height
+	top, bottom := seq.param(0, 1)-1, min(seq.param(1, height), height)-1
+	if height == 0 || top < 0 || top >= bottom {
+		return
+	}
+	terminal.margins.top, terminal.margins.bottom = top, bottom
+	if bottom == height-1 {
+		terminal.margins.bottom = 0
+	}
+	terminal.x, terminal.y = terminal.home()
+}
+
+// setHorizontalMargins handles DECSLRM ("\x1b[left;right s")
+func (terminal *Terminal) setHorizontalMargins(seq sequence) {
+	width := terminal.opt.width
+	left, right := seq.param(0, 1)-1, min(seq.param(1, width), width)-1
+	if width == 0 || terminal.opt.height == 0 || left < 0 || left >= right {
+		return
+	}
+	terminal.margins.left, terminal.margins.right = left, right
+	if right == width-1 {
+		terminal.margins.right = 0
+	}
+	terminal.x, terminal.y = terminal.home()
+}
+
+// inRegion returns whether the cursor is in the scroll region
+func (terminal *Terminal) inRegion() bool {
+	top, bottom, left, right := terminal.region()
+	return terminal.y >= top && terminal.y <= bottom &&
+		// past the right margin, waiting to wrap, counts as inside
+		terminal.x >= left && terminal.x <= right+1
+}
+
+// scroll moves the scroll region up n lines, or down if n is negative
+func (terminal *Terminal) scroll(n int) {
+	if terminal.opt.height == 0 {
+		return
+	}
+	top, bottom, left, right := terminal.region()
+	for len(terminal.screen) <= bottom {
+		terminal.screen = append(terminal.screen, "")
+	}
+	full := left == 0 && (terminal.opt.width == 0 || right == terminal.opt.width-1)
+	bg := terminal.erasedBackground()
+	rows := make([]string, bottom-top+1)
+	for i := range rows {
+		source := ""
+		if from := top + i + n; from >= top && from <= bottom {
+			source = terminal.screen[from]
+		} else if bg != "" && full {
+			source = colored(bg, terminal.columns(top+i))
+		} else if bg != "" {
+			source = colored(bg, right-left+1)
+		}
+		if full {
+			rows[i] = source
+		} else {
+			rows[i] = replaceColumns(terminal.screen[top+i], left, right+1, cut(source, left, right+1))
+		}
+	}
+	copy(terminal.screen[top:], rows)
+	if full {
+		terminal.moveRows(top, bottom, n)
+	}
+}
+
+// reverseIndex moves the cursor up, scrolling down at the top of the scroll region (RI)
+func (terminal *Terminal) reverseIndex() {
+	if top, _, _, _ := terminal.region(); terminal.y == top && terminal.inRegion() {
+		terminal.scroll(-1)
+	} else if terminal.y > terminal.top {
+		terminal.y--
+	}
+}
+
+func (terminal *Terminal) saveCursor() {
+	terminal.saved = &cursor{x: terminal.x, y: terminal.y - terminal.top, style: terminal.style,
+		origin: terminal.modes[OriginMode], charsets: terminal.charsets, gl: terminal.gl}
+}
+
+func (terminal *Terminal) restoreCursor() {
+	saved := terminal.saved
+	if saved == nil {
+		saved = &cursor{}
+	}
+	terminal.modes[OriginMode] = saved.origin
+	terminal.x, terminal.y = terminal.limit(saved.x, terminal.top+saved.y)
+	terminal.style = saved.style
+	if terminal.style == "" {
+		terminal.style = "\x1b[m" // end any style set since saving
+	}
+	terminal.charsets, terminal.gl = saved.charsets, saved.gl
+}
+
+// cut returns columns from (inclusive) to (exclusive) of line, with the style and link in effect
+func cut(line string, from, to int) string {
+	if from >= length(line) {
+		return ""
+	}
+	start, end := pos(line, from), pos(line, min(to, length(line)))
+	text := updateStyle(ansiStyleCodes.FindAllString(line[:start], -1)) + openLink(line[:start]) +
+		line[start:end]
+	if openLink(text) != "" {
+		text += linkEnd
+	}
+	if style := updateStyle(ansiStyleCodes.FindAllString(text, -1)); style != "" &&
+		!ansiResetCode.MatchString(style) {
+		text += "\x1b[m"
+	}
+	return text
+}
+
+// replaceColumns returns line with columns from (inclusive) to (exclusive) replaced by text,
+// padded with spaces. The line is not made longer than it was, except by text.
+func replaceColumns(line string, from, to int, text string) string {
+	padding := max(0, min(to, length(line))-from-length(text))
+	if text == "" && padding == 0 {
+		return line
+	}
+	return print([]string{line}, text+strings.Repeat(" ", padding), from, 0)[0]
+}
@@ -0,0 +1,60 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScrollRegion(t *testing.T) {
+	terminal := NewTerminal(WithSize(10, 5))
+	terminal.Write([]byte("header\x1b[2;4r\x1b[5Hfooter\x1b[4H1\r\n2\r\n3\r\n4"))
+
+	assertEqualsStr(t, "header,2,3,4,footer", strings.Join(terminal.Lines(), ","))
+
+	terminal.Write([]byte("\x1b[2H\x1bMnew"))
+	assertEqualsStr(t, "header,new,2,3,footer", strings.Join(terminal.Lines(), ","))
+}
+
+func TestOriginMode(t *testing.T) {
+	var responses strings.Builder
+	terminal := NewTerminal(WithSize(10, 5), WithResponseWriter(&responses))
+	terminal.Write([]byte("\x1b[2;4r\x1b[?6h\x1b[Ha\x1b[2;3Hb\x1b[9;1Hc\x1b[6n"))
+
+	assertEqualsStr(t, ",a,  b,c", strings.Join(terminal.Lines(), ","))
+	assertEqualsStr(t, "\x1b[3;2R", responses.String())
+
+	terminal.Write([]byte("\x1b[?6l\x1b[Hd"))
+	assertEqualsStr(t, "d,a,  b,c", strings.Join(terminal.Lines(), ","))
+}
+
+func TestLeftRightMargins(t *testing.T) {
+	terminal := NewTerminal(WithSize(10, 3))
+	terminal.Write([]byte("left|....|\r\n" + "\x1b[?69h\x1b[6;9s\x1b[?6h\x1b[Hwrapped text"))
+
+	assertEqualsStr(t, "left|wrap|,     ped ,     text", strings.Join(stripStyles(terminal.Lines()), ","))
+}
+
+func TestLeftRightMarginsScroll(t *testing.T) {
+	terminal := NewTerminal(WithSize(6, 3))
+	terminal.Write([]byte("ab\x1b[1mcd\x1b[mef\r\ngh\x1b[7mij\x1b[mkl\r\nmnopqr" +
+		"\x1b[?69h\x1b[3;4s\x1b[3;3H\x1bD"))
+
+	assertEqualsStr(t, "abijef,ghopkl,mn  qr", strings.Join(stripStyles(terminal.Lines()), ","))
+	assertTrue(t, strings.Contains(terminal.Lines()[0], "\x1b[7mij"))
+	assertTrue(t, strings.HasSuffix(terminal.Lines()[0], "\x1b[mef"))
+}
+
+func TestSaveCursor(t *testing.T) {
+	terminal := NewTerminal(WithSize(10, 3))
+	terminal.Write([]byte("ab\x1b7\x1b[31m\x1b[3;5Hx\x1b8c\x1b[s\r\ny\x1b[uz"))
+
+	assertEqualsStr(t, "abcz,y,    x", strings.Join(stripStyles(terminal.Lines()), ","))
+	assertTrue(t, strings.HasSuffix(terminal.Lines()[0], "\x1b[mcz"))
+}
@@ -0,0 +1,92 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Mode is a DEC private mode, set with "\x1b[?Nh" and reset with "\x1b[?Nl"
+type Mode int
+
+const (
+	ApplicationCursorKeys Mode = 1
+	OriginMode            Mode = 6
+	AutoWrap              Mode = 7  // set by default
+	CursorVisible         Mode = 25 // set by default
+	MouseClicks           Mode = 1000
+	MouseHighlight        Mode = 1001
+	MouseDrag             Mode = 1002
+	MouseMotion           Mode = 1003
+	FocusEvents           Mode = 1004
+	MouseUTF8             Mode = 1005
+	MouseSGR              Mode = 1006
+	BracketedPaste        Mode = 2004
+	SynchronizedOutput    Mode = 2026
+)
+
+// knownModes are reported as set or reset, other modes as not recognized
+var knownModes = map[Mode]bool{ApplicationCursorKeys: true, OriginMode: true, AutoWrap: true,
+	CursorVisible: true, MouseClicks: true, MouseHighlight: true, MouseDrag: true, MouseMotion: true,
+	FocusEvents: true, MouseUTF8: true, MouseSGR: true, BracketedPaste: true, SynchronizedOutput: true,
+	LeftRightMargins: true}
+
+// PrivateMode returns whether mode is set
+func (terminal *Terminal) PrivateMode(mode Mode) bool {
+	return terminal.modes[mode]
+}
+
+// PrivateModes returns all modes that are set, including unknown ones
+func (terminal *Terminal) PrivateModes() []Mode {
+	modes := []Mode{}
+	for mode, set := range terminal.modes {
+		if set {
+			modes = append(modes, mode)
+		}
+	}
+	sort.Slice(modes, func(i, j int) bool { return modes[i] < modes[j] })
+	return modes
+}
+
+// setMode handles "\x1b[?...h" and "\x1b[?...l"
+func (terminal *Terminal) setMode(seq sequence) {
+	set := seq.final == "h"
+	ignored := false
+	for i := range strings.Split(seq.params, ";") {
+		mode := Mode(seq.param(i, 0))
+		ignored = ignored || !knownModes[mode] || !terminal.supports(mode)
+		if !terminal.supports(mode) {
+			continue
+		}
+		if mode == SynchronizedOutput && terminal.onFrame != nil && terminal.modes[mode] != set {
+			terminal.onFrame()
+		}
+		terminal.modes[mode] = set
+		if mode == OriginMode {
+			terminal.x, terminal.y = terminal.home()
+		} else if mode == LeftRightMargins && !set {
+			terminal.margins.left, terminal.margins.right = 0, 0
+		}
+	}
+	if ignored {
+		terminal.unsupported()
+	}
+}
+
+// reportMode answers a request for mode ("\x1b[?N$p")
+func (terminal *Terminal) reportMode(mode Mode) {
+	status := 0 // not recognized
+	if knownModes[mode] && terminal.supports(mode) && terminal.modes[mode] {
+		status = 1
+	} else if knownModes[mode] && terminal.supports(mode) {
+		status = 2
+	}
+	terminal.respond(fmt.Sprintf("\x1b[?%d;%d$y", mode, status))
+}
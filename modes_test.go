@@ -0,0 +1,45 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestModes(t *testing.T) {
+	terminal := NewTerminal()
+	assertTrue(t, terminal.PrivateMode(CursorVisible))
+	assertTrue(t, terminal.PrivateMode(AutoWrap))
+	assertTrue(t, !terminal.PrivateMode(BracketedPaste))
+
+	terminal.Write([]byte("\x1b[?25l\x1b[?1000;1006h\x1b[?2004h\x1b[?1h"))
+	assertTrue(t, !terminal.PrivateMode(CursorVisible))
+	assertTrue(t, terminal.PrivateMode(MouseClicks))
+	assertTrue(t, terminal.PrivateMode(MouseSGR))
+	assertEqualsStr(t, "[1 7 1000 1006 2004]", fmt.Sprint(terminal.PrivateModes()))
+
+	terminal.Write([]byte("\x1b[?1000;1006l\x1b[?2004l\x1b[?1l\x1b[?25h"))
+	assertEqualsStr(t, "[7 25]", fmt.Sprint(terminal.PrivateModes()))
+}
+
+func TestModeReport(t *testing.T) {
+	var responses strings.Builder
+	terminal := NewTerminal(WithResponseWriter(&responses))
+	terminal.Write([]byte("\x1b[?2004h\x1b[?2004$p\x1b[?1004$p\x1b[?9999$p"))
+
+	assertEqualsStr(t, "\x1b[?2004;1$y\x1b[?1004;2$y\x1b[?9999;0$y", responses.String())
+}
+
+func TestAutoWrapOff(t *testing.T) {
+	terminal := NewTerminal(WithSize(5, 3))
+	terminal.Write([]byte("\x1b[?7labcdefg\r\n\x1b[?7h12345678"))
+
+	assertEqualsStr(t, "abcdg,12345,678", strings.Join(terminal.Lines(), ","))
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// newLineMode is the ANSI mode LNM ("\x1b[20h"), which makes line feeds return to the first column
+const newLineMode = 20
+
+// RawLineFeed makes "\n" only move the cursor down, as written by a program with the terminal in
+// raw mode, unless new line mode ("\x1b[20h") is set. By default, "\n" also moves the cursor to
+// the first column, like a TTY translating it to "\r\n".
+func RawLineFeed() Option {
+	return func(o *opt) {
+		o.rawLineFeed = true
+	}
+}
+
+// newLine handles "\n"
+func (terminal *Terminal) newLine() {
+	terminal.lineFeed()
+	if !terminal.opt.rawLineFeed || terminal.newLineMode {
+		terminal.x = 0
+	}
+}
+
+// verticalFeed handles vertical tab and form feed, which are line feeds not translated by a TTY
+func (terminal *Terminal) verticalFeed() {
+	terminal.lineFeed()
+	if terminal.newLineMode {
+		terminal.x = 0
+	}
+}
+
+// setANSIMode handles "\x1b[...h" and "\x1b[...l"
+func (terminal *Terminal) setANSIMode(seq sequence) {
+	ignored := false
+	for i := range strings.Split(seq.params, ";") {
+		if seq.param(i, 0) == newLineMode {
+			terminal.newLineMode = seq.final == "h"
+		} else {
+			ignored = true
+		}
+	}
+	if ignored {
+		terminal.unsupported()
+	}
+}
+
+// reportANSIMode answers a request for mode ("\x1b[N$p")
+func (terminal *Terminal) reportANSIMode(mode int) {
+	status := 0 // not recognized
+	if mode == newLineMode && terminal.newLineMode {
+		status = 1
+	} else if mode == newLineMode {
+		status = 2
+	}
+	terminal.respond(fmt.Sprintf("\x1b[%d;%d$y", mode, status))
+}
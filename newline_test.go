@@ -0,0 +1,67 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLineFeedReturns(t *testing.T) {
+	lines := Capture(strings.NewReader("abc\ndef"))
+
+	assertEquals(t, 2, len(lines))
+	assertEqualsStr(t, "abc", lines[0])
+	assertEqualsStr(t, "def", lines[1])
+}
+
+func TestRawLineFeed(t *testing.T) {
+	lines := Capture(strings.NewReader("abc\ndef\r\nghi"), RawLineFeed())
+
+	assertEquals(t, 3, len(lines))
+	assertEqualsStr(t, "abc", lines[0])
+	assertEqualsStr(t, "   def", lines[1])
+	assertEqualsStr(t, "ghi", lines[2])
+}
+
+func TestRawLineFeedWrite(t *testing.T) {
+	terminal := NewTerminal(RawLineFeed())
+	terminal.Write([]byte("ab\n"))
+	terminal.Write([]byte("cd"))
+
+	lines := terminal.Lines()
+	assertEquals(t, 2, len(lines))
+	assertEqualsStr(t, "  cd", lines[1])
+}
+
+func TestNewLineMode(t *testing.T) {
+	lines := Capture(strings.NewReader("\x1b[20habc\ndef\x1b[20l\nghi"), RawLineFeed())
+
+	assertEquals(t, 3, len(lines))
+	assertEqualsStr(t, "abc", lines[0])
+	assertEqualsStr(t, "def", lines[1])
+	assertEqualsStr(t, "   ghi", lines[2])
+}
+
+func TestVerticalTabAndFormFeed(t *testing.T) {
+	lines := Capture(strings.NewReader("ab\x0bcd\x0cef\x1b[20h\x0bgh"))
+
+	assertEquals(t, 4, len(lines))
+	assertEqualsStr(t, "ab", lines[0])
+	assertEqualsStr(t, "  cd", lines[1])
+	assertEqualsStr(t, "    ef", lines[2])
+	assertEqualsStr(t, "gh", lines[3])
+}
+
+func TestNewLineModeReport(t *testing.T) {
+	var responses strings.Builder
+	terminal := NewTerminal(WithResponseWriter(&responses))
+	terminal.Write([]byte("\x1b[20$p\x1b[20h\x1b[20$p\x1b[4$p"))
+
+	assertEqualsStr(t, "\x1b[20;2$y\x1b[20;1$y\x1b[4;0$y", responses.String())
+}
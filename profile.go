@@ -0,0 +1,91 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+// Profile is how a particular terminal behaves where terminals disagree
+type Profile struct {
+	primaryDA, secondaryDA string // answers to device attributes queries, "" to not answer
+	bce                    bool   // erased cells get the background color
+	eraseScrollback        bool   // "\x1b[3J" erases lines scrolled out, instead of being ignored
+	modes                  []Mode // supported private modes
+}
+
+// XTerm is xterm, as a VT220 with colors
+var XTerm = Profile{
+	primaryDA:       "\x1b[?62;22c",
+	secondaryDA:     "\x1b[>1;0;0c",
+	bce:             true,
+	eraseScrollback: true,
+	modes: []Mode{ApplicationCursorKeys, OriginMode, AutoWrap, CursorVisible, LeftRightMargins,
+		MouseClicks, MouseHighlight, MouseDrag, MouseMotion, FocusEvents, MouseUTF8, MouseSGR,
+		BracketedPaste, SynchronizedOutput},
+}
+
+// VT100 is a DEC VT100 with advanced video option
+var VT100 = Profile{
+	primaryDA: "\x1b[?1;2c",
+	modes:     []Mode{ApplicationCursorKeys, OriginMode, AutoWrap},
+}
+
+// LinuxConsole is the Linux virtual console
+var LinuxConsole = Profile{
+	primaryDA:       "\x1b[?6c",
+	bce:             true,
+	eraseScrollback: true,
+	modes:           []Mode{ApplicationCursorKeys, OriginMode, AutoWrap, CursorVisible, MouseClicks},
+}
+
+// Tmux is tmux, as seen by programs running inside it
+var Tmux = Profile{
+	primaryDA:       "\x1b[?1;2c",
+	secondaryDA:     "\x1b[>84;0;0c",
+	eraseScrollback: true,
+	modes: []Mode{ApplicationCursorKeys, OriginMode, AutoWrap, CursorVisible, LeftRightMargins,
+		MouseClicks, MouseDrag, MouseMotion, FocusEvents, MouseUTF8, MouseSGR, BracketedPaste,
+		SynchronizedOutput},
+}
+
+// WithProfile makes the terminal behave like, and identify as, another terminal. Without a
+// profile, all modes are supported, BCE is on and "\x1b[3J" erases the screen like "\x1b[2J".
+func WithProfile(profile Profile) Option {
+	return func(o *opt) {
+		o.profile = &profile
+		o.primaryDA, o.secondaryDA = profile.primaryDA, profile.secondaryDA
+	}
+}
+
+// supports returns whether mode can be set
+func (terminal *Terminal) supports(mode Mode) bool {
+	if terminal.opt.profile == nil {
+		return true
+	}
+	for _, supported := range terminal.opt.profile.modes {
+		if mode == supported {
+			return true
+		}
+	}
+	return false
+}
+
+// eraseScrollback removes the lines which have scrolled out of the screen
+func (terminal *Terminal) eraseScrollback() {
+	top := terminal.top
+	terminal.screen = terminal.screen[min(top, len(terminal.screen)):]
+	terminal.y -= top
+	terminal.top = 0
+	marks := []mark{}
+	for _, m := range terminal.marks {
+		if m.y >= top {
+			m.y -= top
+			marks = append(marks, m)
+		}
+	}
+	terminal.marks = marks
+	terminal.clearRows(0, top)
+	terminal.moveRows(0, top+len(terminal.screen), top)
+}
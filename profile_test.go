@@ -0,0 +1,83 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProfileIdentity(t *testing.T) {
+	for _, test := range []struct {
+		profile Profile
+		want    string
+	}{
+		{XTerm, "\x1b[?62;22c\x1b[>1;0;0c"},
+		{VT100, "\x1b[?1;2c"},
+		{LinuxConsole, "\x1b[?6c"},
+		{Tmux, "\x1b[?1;2c\x1b[>84;0;0c"},
+	} {
+		var responses strings.Builder
+		terminal := NewTerminal(WithProfile(test.profile), WithResponseWriter(&responses))
+		terminal.Write([]byte("\x1b[c\x1b[>c"))
+		assertEqualsStr(t, test.want, responses.String())
+	}
+}
+
+func TestProfileModes(t *testing.T) {
+	var responses strings.Builder
+	terminal := NewTerminal(WithProfile(VT100), WithResponseWriter(&responses))
+	terminal.Write([]byte("\x1b[?2004h\x1b[?1h\x1b[?2004$p\x1b[?1$p"))
+
+	assertTrue(t, !terminal.PrivateMode(BracketedPaste))
+	assertTrue(t, terminal.PrivateMode(ApplicationCursorKeys))
+	assertEqualsStr(t, "\x1b[?2004;0$y\x1b[?1;1$y", responses.String())
+}
+
+func TestProfileEraseScrollback(t *testing.T) {
+	input := "one\r\ntwo\r\nthree\x1b[3J"
+
+	terminal := NewTerminal(WithSize(6, 2), WithProfile(XTerm))
+	terminal.Write([]byte(input + "!"))
+	assertEqualsStr(t, "two,three!", strings.Join(terminal.Lines(), ","))
+
+	terminal = NewTerminal(WithSize(6, 2), WithProfile(VT100))
+	terminal.Write([]byte(input + "!"))
+	assertEqualsStr(t, "one,two,three!", strings.Join(terminal.Lines(), ","))
+
+	terminal = NewTerminal(WithSize(6, 2))
+	terminal.Write([]byte(input + "!"))
+	assertEqualsStr(t, "one,!", strings.Join(terminal.Lines(), ","))
+}
+
+func TestProfileBCE(t *testing.T) {
+	terminal := NewTerminal(WithSize(4, 1), WithProfile(Tmux))
+	terminal.Write([]byte("\x1b[44m\x1b[K"))
+	assertEqualsStr(t, "", strings.Join(stripStyles(terminal.Lines()), ","))
+
+	terminal = NewTerminal(WithSize(4, 1), WithProfile(LinuxConsole))
+	terminal.Write([]byte("\x1b[44m\x1b[K"))
+	assertEqualsStr(t, "    ", strings.Join(stripStyles(terminal.Lines()), ","))
+}
+
+func TestProfileDisableBCE(t *testing.T) {
+	for _, opts := range [][]Option{{DisableBCE(), WithProfile(XTerm)}, {WithProfile(XTerm), DisableBCE()}} {
+		terminal := NewTerminal(append([]Option{WithSize(4, 1)}, opts...)...)
+		terminal.Write([]byte("\x1b[44m\x1b[K"))
+		assertEqualsStr(t, "", strings.Join(stripStyles(terminal.Lines()), ","))
+	}
+}
+
+// All profiles defer wrapping to the next character, like most terminals (terminfo xenl)
+func TestProfilePendingWrap(t *testing.T) {
+	for _, profile := range []Profile{XTerm, VT100, LinuxConsole, Tmux} {
+		terminal := NewTerminal(WithSize(4, 3), WithProfile(profile))
+		terminal.Write([]byte("abcd\rX"))
+		assertEqualsStr(t, "Xbcd", strings.Join(terminal.Lines(), ","))
+	}
+}
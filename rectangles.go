@@ -0,0 +1,160 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// protectMark precedes each character protected from selective erase (DECSCA). It is a control
+// sequence, so it takes no space on screen, and it is removed from output.
+const protectMark = "\x1b[1Q"
+
+// protect marks each character of text, which may contain escape codes, as protected
+func protect(text string) string {
+	var result strings.Builder
+	for text != "" {
+		if text[0] == '\x1b' {
+			if loc := allAnsiCodes.FindStringIndex(text); loc != nil && loc[0] == 0 {
+				result.WriteString(text[:loc[1]])
+				text = text[loc[1]:]
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(text)
+		result.WriteString(protectMark + text[:size])
+		text = text[size:]
+	}
+	return result.String()
+}
+
+// withoutProtection returns lines without protectMark
+func withoutProtection(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = strings.ReplaceAll(line, protectMark, "")
+	}
+	return result
+}
+
+// rectangle returns the area given by parameters top, left, bottom and right (1-based, starting
+// at parameter i), as first and last row (absolute) and column
+func (terminal *Terminal) rectangle(seq sequence, i int) (top, left, bottom, right int, ok bool) {
+	columns, rows := terminal.size()
+	x, y := terminal.home()
+	param := func(i, def int) int {
+		if n := seq.param(i, def); n > 0 {
+			return n
+		}
+		return def
+	}
+	top, left = y+param(i, 1)-1, x+param(i+1, 1)-1
+	bottom = min(y+param(i+2, rows)-1, terminal.top+rows-1)
+	right = min(x+param(i+3, columns)-1, columns-1)
+	return top, left, bottom, right, top <= bottom && left <= right
+}
+
+// fillRectangle handles DECFRA ("\x1b[char;top;left;bottom;right$x"), filling with the current style
+func (terminal *Terminal) fillRectangle(seq sequence) {
+	top, left, bottom, right, ok := terminal.rectangle(seq, 1)
+	c := rune(seq.param(0, 0))
+	if !ok || c < ' ' || c == 0x7f {
+		return
+	}
+	text := strings.Repeat(terminal.translate(string(c)), right-left+1)
+	if terminal.protected {
+		text = protect(text)
+	}
+	if terminal.style != "" {
+		text = terminal.style + text + "\x1b[m"
+	}
+	for y := top; y <= bottom; y++ {
+		terminal.screen = print(terminal.screen, text, left, y)
+	}
+}
+
+// eraseRectangle handles DECERA ("\x1b[top;left;bottom;right$z")
+func (terminal *Terminal) eraseRectangle(seq sequence) {
+	top, left, bottom, right, ok := terminal.rectangle(seq, 0)
+	for y := top; ok && y <= bottom && y < len(terminal.screen); y++ {
+		terminal.screen[y] = replaceColumns(terminal.screen[y], left, right+1, "")
+	}
+}
+
+// selectiveEraseRectangle handles DECSERA ("\x1b[top;left;bottom;right${")
+func (terminal *Terminal) selectiveEraseRectangle(seq sequence) {
+	top, left, bottom, right, ok := terminal.rectangle(seq, 0)
+	for y := top; ok && y <= bottom && y < len(terminal.screen); y++ {
+		terminal.screen[y] = eraseUnprotected(terminal.screen[y], left, right+1)
+	}
+}
+
+// copyRectangle handles DECCRA ("\x1b[top;left;bottom;right;page;top;left;page$v")
+func (terminal *Terminal) copyRectangle(seq sequence) {
+	top, left, bottom, right, ok := terminal.rectangle(seq, 0)
+	x, y := terminal.home()
+	columns, rows := terminal.size()
+	toTop, toLeft := y+max(1, seq.param(5, 1))-1, x+max(1, seq.param(6, 1))-1
+	width := min(right-left+1, columns-toLeft)
+	height := min(bottom-top+1, terminal.top+rows-toTop)
+	if !ok || width <= 0 || height <= 0 {
+		return
+	}
+	parts := make([]string, height) // copied first, as the areas may overlap
+	for i := range parts {
+		if top+i < len(terminal.screen) {
+			parts[i] = cut(terminal.screen[top+i], left, left+width)
+		}
+	}
+	for len(terminal.screen) < toTop+height {
+		terminal.screen = append(terminal.screen, "")
+	}
+	for i, part := range parts {
+		terminal.screen[toTop+i] = replaceColumns(terminal.screen[toTop+i], toLeft, toLeft+width, part)
+	}
+}
+
+// selectiveErase handles DECSED ("\x1b[?J") and DECSEL ("\x1b[?K"), erasing only unprotected
+// characters
+func (terminal *Terminal) selectiveErase(seq sequence, display bool) {
+	mode := seq.param(0, 0)
+	if mode > 2 {
+		return
+	}
+	x, y := terminal.limit(terminal.x, terminal.y)
+	columns, rows := terminal.size()
+	first, last := y, y
+	if display && mode == 0 {
+		last = terminal.top + rows - 1
+	} else if display && mode == 1 {
+		first = terminal.top
+	} else if display {
+		first, last = terminal.top, terminal.top+rows-1
+	}
+	for row := first; row <= last && row < len(terminal.screen); row++ {
+		from, to := 0, columns
+		if mode == 0 && row == y { // to end
+			from = x
+		} else if mode == 1 && row == y { // to beginning
+			to = x + 1
+		}
+		terminal.screen[row] = eraseUnprotected(terminal.screen[row], from, to)
+	}
+}
+
+// eraseUnprotected returns line with unprotected characters in columns from (inclusive) to
+// (exclusive) replaced by spaces
+func eraseUnprotected(line string, from, to int) string {
+	for x := from; x < min(to, length(line)); x++ {
+		if !strings.Contains(line[pos(line, x):pos(line, x+1)], protectMark) {
+			line = replaceColumns(line, x, x+1, " ")
+		}
+	}
+	return line
+}
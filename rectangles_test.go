@@ -0,0 +1,47 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFillRectangle(t *testing.T) {
+	terminal := NewTerminal(WithSize(8, 4))
+	terminal.Write([]byte("abcdefgh\r\nijklmnop\r\n\x1b[31m\x1b[35;2;2;2;4$x\x1b[m\x1b[46;3;7$x"))
+
+	assertEqualsStr(t, "abcdefgh,i###mnop,      ..,      ..", strings.Join(stripStyles(terminal.Lines()), ","))
+	assertEqualsStr(t, "i\x1b[31m###\x1b[mmnop", terminal.Lines()[1])
+}
+
+func TestEraseRectangle(t *testing.T) {
+	terminal := NewTerminal(WithSize(8, 4))
+	terminal.Write([]byte("abcdefgh\r\nijklmnop\r\nqrst\x1b[1;2;3;3$z\x1b[2;7$z"))
+
+	assertEqualsStr(t, "a  defgh,i  lmn  ,q  t", strings.Join(terminal.Lines(), ","))
+}
+
+func TestCopyRectangle(t *testing.T) {
+	terminal := NewTerminal(WithSize(8, 4))
+	terminal.Write([]byte("ab\x1b[1mcd\x1b[mefgh\r\nijklmnop\x1b[1;3;2;4;1;3;6;1$v"))
+
+	assertEqualsStr(t, "abcdefgh,ijklmnop,     cd,     kl", strings.Join(stripStyles(terminal.Lines()), ","))
+	assertEqualsStr(t, "     \x1b[1mcd\x1b[m", terminal.Lines()[2])
+}
+
+func TestSelectiveErase(t *testing.T) {
+	terminal := NewTerminal(WithSize(10, 4))
+	terminal.Write([]byte("ab\x1b[1\"qcd\x1b[0\"qef\r\n" + "gh\x1b[1\"qij\x1b[2\"qkl\r\n" + "mn\x1b[1\"qop\x1b[\"qqr" +
+		"\x1b[1;1;1;10${\x1b[2;4H\x1b[?K\x1b[3;3H\x1b[?1J"))
+
+	assertEqualsStr(t, "  cd  ,  ij  ,  opqr", strings.Join(terminal.Lines(), ","))
+
+	terminal.Write([]byte("\x1b[?2J"))
+	assertEqualsStr(t, "  cd  ,  ij  ,  op  ", strings.Join(terminal.Lines(), ","))
+}
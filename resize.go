@@ -0,0 +1,142 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import "strings"
+
+// WithReflow makes resizing rewrap lines which were wrapped at the last column, like most
+// modern terminals do
+func WithReflow() Option {
+	return func(o *opt) {
+		o.reflow = true
+	}
+}
+
+// Resize changes the number of columns and rows, like WithSize. The cursor stays on screen,
+// lines scroll out at the top when there are fewer rows, and back in when there are more.
+// Lines are cut at the last column, or rewrapped with WithReflow.
+func (terminal *Terminal) Resize(columns, rows int) {
+	columns, rows = max(0, columns), max(0, rows)
+	if columns != terminal.opt.width && columns > 0 && terminal.opt.width > 0 && terminal.opt.reflow {
+		terminal.reflow(columns)
+	} else if columns > 0 {
+		for y := terminal.top; y < len(terminal.screen); y++ {
+			if length(terminal.screen[y]) > columns {
+				terminal.screen[y] = cut(terminal.screen[y], 0, columns)
+				delete(terminal.wrapped, y)
+			}
+		}
+		terminal.x = min(terminal.x, columns-1)
+	}
+	terminal.opt.width, terminal.opt.height = columns, rows
+	terminal.margins = margins{}
+	if rows > 0 {
+		bottom := max(terminal.y, len(terminal.screen)-1)
+		top := max(terminal.top, terminal.y-rows+1)
+		terminal.top = max(0, min(top, bottom-rows+1))
+		terminal.screen = terminal.screen[:min(len(terminal.screen), terminal.top+rows)]
+	}
+}
+
+// reflow rewraps lines which were wrapped, so they fit columns
+func (terminal *Terminal) reflow(columns int) {
+	width := terminal.opt.width
+	screen := []string{}
+	wrapped := map[int]bool{}
+	starts := map[int]int{} // row where the line of each row starts
+	moved := map[int]int{}  // new row of each row which starts a line
+	for y := 0; y < len(terminal.screen); y++ {
+		start, line := y, terminal.screen[y]
+		starts[y] = y
+		for terminal.wrapped[y] && y+1 < len(terminal.screen) {
+			line += strings.Repeat(" ", max(0, width-length(terminal.screen[y])))
+			y++
+			line += terminal.screen[y]
+			starts[y] = start
+		}
+		moved[start] = len(screen)
+		if length(line) <= columns {
+			screen = append(screen, line)
+			continue
+		}
+		for i := 0; i < length(line); i += columns {
+			if i > 0 {
+				wrapped[len(screen)-1] = true
+			}
+			screen = append(screen, cut(line, i, i+columns))
+		}
+	}
+	// position returns where column x of row y has moved
+	position := func(x, y int) (int, int) {
+		if y >= len(terminal.screen) { // below the lines
+			return x, len(screen) + y - len(terminal.screen)
+		}
+		pending := x >= width // waiting to wrap
+		if pending {
+			x = width - 1
+		}
+		offset := (y-starts[y])*width + x
+		x, y = offset%columns, moved[starts[y]]+offset/columns
+		if pending {
+			x++
+		}
+		return x, y
+	}
+	terminal.x, terminal.y = position(terminal.x, terminal.y)
+	_, terminal.top = position(0, terminal.top)
+	for i, m := range terminal.marks {
+		terminal.marks[i].x, terminal.marks[i].y = position(m.x, m.y)
+	}
+	terminal.screen = screen
+	terminal.wrapped = wrapped
+	terminal.lineSizes = nil
+}
+
+func (terminal *Terminal) setWrapped(y int) {
+	if terminal.wrapped == nil {
+		terminal.wrapped = map[int]bool{}
+	}
+	terminal.wrapped[y] = true
+}
+
+// clearRows removes the attributes of rows from (inclusive) to (exclusive), as they are erased
+func (terminal *Terminal) clearRows(from, to int) {
+	for y := range terminal.lineSizes {
+		if y >= from && y < to {
+			delete(terminal.lineSizes, y)
+		}
+	}
+	for y := range terminal.wrapped {
+		if y >= from && y < to {
+			delete(terminal.wrapped, y)
+		}
+	}
+}
+
+// moveRows moves the attributes of rows from (inclusive) to (inclusive) up n rows, or down if n
+// is negative, as the rows are scrolled
+func (terminal *Terminal) moveRows(from, to, n int) {
+	sizes := map[int]byte{}
+	for y, size := range terminal.lineSizes {
+		if y < from || y > to {
+			sizes[y] = size
+		} else if y-n >= from && y-n <= to {
+			sizes[y-n] = size
+		}
+	}
+	terminal.lineSizes = sizes
+	wrapped := map[int]bool{}
+	for y := range terminal.wrapped {
+		if y < from || y > to {
+			wrapped[y] = true
+		} else if y-n >= from && y-n <= to {
+			wrapped[y-n] = true
+		}
+	}
+	terminal.wrapped = wrapped
+}
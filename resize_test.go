@@ -0,0 +1,67 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	terminal := NewTerminal(WithSize(6, 3))
+	terminal.Write([]byte("one\r\ntwo\r\nthree"))
+
+	terminal.Resize(4, 2)
+	assertEqualsStr(t, "two,thre", strings.Join(terminal.Screen(), ","))
+	terminal.Write([]byte("!"))
+	assertEqualsStr(t, "two,thr!", strings.Join(terminal.Screen(), ","))
+
+	terminal.Resize(8, 3)
+	assertEqualsStr(t, "one,two,thr!", strings.Join(terminal.Screen(), ","))
+}
+
+func TestResizeSequence(t *testing.T) {
+	var responses strings.Builder
+	terminal := NewTerminal(WithSize(80, 24), WithResponseWriter(&responses))
+	terminal.Write([]byte("\x1b[8;5;10t\x1b[18t\x1b[8;;20t\x1b[18t"))
+
+	assertEqualsStr(t, "\x1b[8;5;10t\x1b[8;5;20t", responses.String())
+}
+
+func TestReflow(t *testing.T) {
+	terminal := NewTerminal(WithSize(6, 4), WithReflow())
+	terminal.Write([]byte("$ echo \x1b[1mlonger\x1b[m line\r\nok\r\n$ "))
+	assertEqualsStr(t, "$ echo, longe,r line,ok,$ ", strings.Join(stripStyles(terminal.Lines()), ","))
+
+	terminal.Resize(12, 4)
+	assertEqualsStr(t, "$ echo longe,r line,ok,$ ", strings.Join(stripStyles(terminal.Screen()), ","))
+	terminal.Write([]byte("x"))
+	assertEqualsStr(t, "$ x", stripStyles(terminal.Screen())[3])
+
+	terminal.Resize(20, 4)
+	assertEqualsStr(t, "$ echo longer line,ok,$ x", strings.Join(stripStyles(terminal.Screen()), ","))
+	bold := ""
+	for _, run := range runs(terminal.Screen()[0]) {
+		if run.attr.bold {
+			bold += run.text
+		}
+	}
+	assertEqualsStr(t, "longer", bold)
+
+	terminal.Resize(5, 8)
+	assertEqualsStr(t, "$ ech,o lon,ger l,ine,ok,$ x", strings.Join(stripStyles(terminal.Screen()), ","))
+}
+
+func TestReflowPendingWrap(t *testing.T) {
+	terminal := NewTerminal(WithSize(4, 2), WithReflow())
+	terminal.Write([]byte("abcdef"))
+
+	terminal.Resize(3, 3)
+	terminal.Write([]byte("g"))
+	assertEqualsStr(t, "abc,def,g", strings.Join(terminal.Screen(), ","))
+}
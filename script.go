@@ -0,0 +1,94 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var scriptHeader = regexp.MustCompile("^Script started on [^\n]*\n")
+var scriptFooter = regexp.MustCompile("\n?Script done on [^\n]*\n?$")
+var scriptSize = regexp.MustCompile(`\b(COLUMNS|LINES)="([0-9]+)"`)
+
+// ReadScript reads a typescript made by script(1), without the "Script started/done" lines.
+// COLUMNS and LINES, if recorded, are used as size.
+// Timing (script -t or --log-timing, classic or advanced format) is optional; without it, the
+// recording is a single event. Only output is replayed, input entries in the timing are skipped.
+func ReadScript(typescript io.Reader, timing io.Reader) (Recording, error) {
+	data, err := io.ReadAll(typescript)
+	if err != nil {
+		return Recording{}, fmt.Errorf("reading typescript: %w", err)
+	}
+	text := string(data)
+	recording := Recording{}
+	if header := scriptHeader.FindString(text); header != "" {
+		text = text[len(header):]
+		for _, match := range scriptSize.FindAllStringSubmatch(header, -1) {
+			recording.setSize(match[1], match[2])
+		}
+	}
+	if timing == nil {
+		recording.Events = []Event{{Data: scriptFooter.ReplaceAllString(text, "")}}
+		return recording, nil
+	}
+	scanner := bufio.NewScanner(timing)
+	seconds := 0.0
+	offset := 0
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		stream := "O"
+		if len(fields) > 2 { // advanced format: stream, delay, count or header
+			stream = fields[0]
+			fields = fields[1:]
+		}
+		delay, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil || len(fields) < 2 {
+			return recording, fmt.Errorf("invalid timing on line %d: %q", line, scanner.Text())
+		}
+		seconds += delay
+		switch stream {
+		case "O":
+			count, err := strconv.Atoi(fields[1])
+			if err != nil || count < 0 {
+				return recording, fmt.Errorf("invalid timing on line %d: %q", line, scanner.Text())
+			}
+			end := min(offset+count, len(text))
+			recording.Events = append(recording.Events, Event{Time: seconds2duration(seconds), Data: text[offset:end]})
+			offset = end
+		case "H":
+			if len(fields) > 2 {
+				recording.setSize(fields[1], fields[2])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return recording, fmt.Errorf("reading timing: %w", err)
+	}
+	return recording, nil
+}
+
+func (recording *Recording) setSize(name, value string) {
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return
+	}
+	switch name {
+	case "COLUMNS":
+		recording.Width = size
+	case "LINES":
+		recording.Height = size
+	}
+}
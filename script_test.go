@@ -0,0 +1,81 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const typescript = "Script started on 2023-06-01 10:00:00+02:00 [COMMAND=\"ls\" TERM=\"xterm\" TTY=\"/dev/pts/3\" COLUMNS=\"10\" LINES=\"2\"]\n" +
+	"one\r\ntwo\r\nthree\r\n" +
+	"\nScript done on 2023-06-01 10:00:01+02:00 [COMMAND_EXIT_CODE=\"0\"]\n"
+
+func TestReadScript(t *testing.T) {
+	recording, err := ReadScript(strings.NewReader(typescript), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 10, recording.Width)
+	assertEquals(t, 2, recording.Height)
+	assertEquals(t, 1, len(recording.Events))
+	assertEqualsStr(t, "one\r\ntwo\r\nthree\r\n", recording.Events[0].Data)
+}
+
+func TestReadScriptOldHeader(t *testing.T) {
+	recording, err := ReadScript(strings.NewReader(
+		"Script started on Thu 01 Jun 2023 10:00:00 AM CEST\nhello\nScript done on Thu 01 Jun 2023 10:00:01 AM CEST\n"), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 0, recording.Width)
+	assertEqualsStr(t, "hello", recording.Events[0].Data)
+}
+
+func TestReadScriptTiming(t *testing.T) {
+	recording, err := ReadScript(strings.NewReader(typescript), strings.NewReader("0.5 5\n1.0 5\n0.25 7\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, len(recording.Events))
+	assertEquals(t, int(1500*time.Millisecond), int(recording.Events[1].Time))
+	assertEqualsStr(t, "two\r\n", recording.Events[1].Data)
+	assertEqualsStr(t, "three\r\n", recording.Events[2].Data)
+}
+
+func TestReadScriptAdvancedTiming(t *testing.T) {
+	timing := "H 0.000000 COLUMNS 3\nH 0.000000 LINES 5\nO 0.5 5\nI 0.25 1\nO 0.25 5\n"
+	recording, err := ReadScript(strings.NewReader("one\r\ntwo\r\n"), strings.NewReader(timing))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 3, recording.Width)
+	assertEquals(t, 5, recording.Height)
+	assertEquals(t, 2, len(recording.Events))
+	assertEquals(t, int(time.Second), int(recording.Events[1].Time))
+}
+
+func TestReadScriptInvalidTiming(t *testing.T) {
+	_, err := ReadScript(strings.NewReader(typescript), strings.NewReader("0.5 5\nfoo\n"))
+
+	if err == nil {
+		t.Errorf("Expected error")
+	}
+}
+
+func TestScriptScreenAt(t *testing.T) {
+	recording, _ := ReadScript(strings.NewReader(typescript), strings.NewReader("0.5 5\n1.0 5\n0.25 7\n"))
+
+	assertEqualsStr(t, "one", strings.Join(recording.ScreenAt(time.Second), ","))
+	assertEqualsStr(t, "three", strings.Join(recording.ScreenAt(2*time.Second), ","))
+}
@@ -0,0 +1,116 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// sourceMark, with a byte offset, precedes each printed character when mapping sources. Like
+// protectMark, it takes no space on screen, and it is removed from output.
+const sourceMark = "\x1b[2;"
+
+var sourceMarks = regexp.MustCompile("\x1b\\[2;[0-9]+Q")
+
+// Source is where the character of a cell was written
+type Source struct {
+	Offset int           // number of bytes of output before the character, counting all writes, or -1
+	Time   time.Duration // when it was written, since start (as recorded, for a Recording)
+}
+
+// WithSourceMap makes the terminal remember where each character on screen was written, for
+// SourceMap
+func WithSourceMap() Option {
+	return func(o *opt) {
+		o.sourceMap = true
+	}
+}
+
+// written is output written from offset on, at time
+type written struct {
+	offset int
+	time   time.Duration
+}
+
+// SourceMap returns the source of each cell of Lines, by row and column. Cells not printed to,
+// e.g. erased ones, have offset -1. Without WithSourceMap, all offsets are -1.
+func (terminal *Terminal) SourceMap() [][]Source {
+	rows := [][]Source{}
+	for _, line := range terminal.joined(0) {
+		row := []Source{}
+		offset := -1
+		for line != "" {
+			if line[0] == '\x1b' {
+				if loc := allAnsiCodes.FindStringIndex(line); loc != nil && loc[0] == 0 {
+					if code := line[:loc[1]]; sourceMarks.MatchString(code) {
+						offset = number(code[len(sourceMark) : len(code)-1])
+					}
+					line = line[loc[1]:]
+					continue
+				}
+			}
+			row = append(row, terminal.source(offset))
+			offset = -1
+			_, size := utf8.DecodeRuneInString(line)
+			line = line[size:]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// source returns the Source of the character written at offset
+func (terminal *Terminal) source(offset int) Source {
+	i := sort.Search(len(terminal.writes), func(i int) bool { return terminal.writes[i].offset > offset })
+	if offset < 0 || i == 0 {
+		return Source{Offset: offset}
+	}
+	return Source{Offset: offset, Time: terminal.writes[i-1].time}
+}
+
+// write records the time of output written from offset on
+func (terminal *Terminal) write(offset int) {
+	if terminal.opt.sourceMap {
+		terminal.writes = append(terminal.writes, written{offset, terminal.elapsed()})
+	}
+}
+
+// sourced returns text, which starts at offset, with each character marked with its offset
+func (terminal *Terminal) sourced(text string, offset int) string {
+	if !terminal.opt.sourceMap {
+		return text
+	}
+	var result strings.Builder
+	for i := 0; i < len(text); {
+		if text[i] == '\x1b' {
+			if loc := allAnsiCodes.FindStringIndex(text[i:]); loc != nil && loc[0] == 0 {
+				result.WriteString(text[i : i+loc[1]])
+				i += loc[1]
+				continue
+			}
+		}
+		_, size := utf8.DecodeRuneInString(text[i:])
+		result.WriteString(sourceMark + strconv.Itoa(offset+i) + "Q" + text[i:i+size])
+		i += size
+	}
+	return result.String()
+}
+
+// withoutSources returns lines without sourceMarks
+func withoutSources(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = sourceMarks.ReplaceAllString(line, "")
+	}
+	return result
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// offsets returns the offsets of sources, by row
+func offsets(sources [][]Source) string {
+	rows := []string{}
+	for _, row := range sources {
+		offsets := []int{}
+		for _, source := range row {
+			offsets = append(offsets, source.Offset)
+		}
+		rows = append(rows, fmt.Sprint(offsets))
+	}
+	return strings.Join(rows, ",")
+}
+
+func TestSourceMap(t *testing.T) {
+	terminal := NewTerminal(WithSourceMap())
+	terminal.Write([]byte("ab\x1b[1mc\x1b[m\nxyz\rQ"))
+	terminal.Write([]byte("\x1b[2C\x1b[K\x1b[4G!"))
+
+	assertEqualsStr(t, "ab\x1b[1mc\x1b[m,\x1b[mQyz!", strings.Join(terminal.Lines(), ","))
+	assertEqualsStr(t, "[0 1 6],[15 12 13 27]", offsets(terminal.SourceMap()))
+}
+
+func TestSourceMapOff(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte("ab\nc"))
+
+	assertEqualsStr(t, "[-1 -1],[-1]", offsets(terminal.SourceMap()))
+}
+
+func TestSourceMapKeepsOutput(t *testing.T) {
+	input := "\x1b[31ma\x1b(0qq\x1b(B\x1b[m\x1b]8;;http://example.com\x1b\\link\x1b]8;;\x1b\\\n" +
+		"\x1b[1\"qsafe\x1b[0\"q erased\x1b[1;1;2;20${"
+
+	assertEqualsStr(t, strings.Join(Capture(strings.NewReader(input), WithSize(20, 5)), "\n"),
+		strings.Join(Capture(strings.NewReader(input), WithSize(20, 5), WithSourceMap()), "\n"))
+}
+
+func TestSourceMapMoved(t *testing.T) {
+	terminal := NewTerminal(WithSize(5, 2), WithSourceMap())
+	terminal.Write([]byte("abcdefg\nhi"))
+
+	assertEqualsStr(t, "fg,hi", strings.Join(terminal.Screen(), ","))
+	assertEqualsStr(t, "[0 1 2 3 4],[5 6],[8 9]", offsets(terminal.SourceMap()))
+}
+
+func TestSourceMapTime(t *testing.T) {
+	recording := Recording{Events: []Event{
+		{Time: 1 * time.Second, Data: "ab"},
+		{Time: 2 * time.Second, Data: "\x1b["},
+		{Time: 3 * time.Second, Data: "Cc"},
+	}}
+	sources := recording.Replay(WithSourceMap()).SourceMap()
+
+	assertEquals(t, 1, len(sources))
+	assertEqualsStr(t, "[{0 1s} {1 1s} {-1 0s} {5 3s}]", fmt.Sprint(sources[0]))
+}
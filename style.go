@@ -0,0 +1,199 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// attributes is the text style set by SGR (Select Graphic Rendition) codes
+type attributes struct {
+	bold, faint, italic, underline, blink, inverse, hidden, strike bool
+	fg, bg                                                         color
+}
+
+// color is an index in the 256 color palette, an RGB value (with rgbColor set), or defaultColor
+type color int32
+
+const defaultColor color = -1
+const rgbColor color = 1 << 24
+
+var defaultForeground = rgbColor | 0xe5e5e5
+var defaultBackground = rgbColor | 0x000000
+
+var palette16 = []color{
+	0x000000, 0xcd0000, 0x00cd00, 0xcdcd00, 0x0000ee, 0xcd00cd, 0x00cdcd, 0xe5e5e5,
+	0x7f7f7f, 0xff0000, 0x00ff00, 0xffff00, 0x5c5cff, 0xff00ff, 0x00ffff, 0xffffff,
+}
+
+func newAttributes() attributes {
+	return attributes{fg: defaultColor, bg: defaultColor}
+}
+
+// apply updates attributes from an SGR code, e.g. "\x1b[1;31m"
+func (attr *attributes) apply(code string) {
+	params := strings.Split(strings.TrimSuffix(strings.TrimPrefix(code, "\x1b["), "m"), ";")
+	for i := 0; i < len(params); i++ {
+		n := 0
+		if params[i] != "" {
+			n = number(params[i])
+		}
+		switch {
+		case n == 0:
+			*attr = newAttributes()
+		case n == 1:
+			attr.bold = true
+		case n == 2:
+			attr.faint = true
+		case n == 3:
+			attr.italic = true
+		case n == 4:
+			attr.underline = true
+		case n == 5 || n == 6:
+			attr.blink = true
+		case n == 7:
+			attr.inverse = true
+		case n == 8:
+			attr.hidden = true
+		case n == 9:
+			attr.strike = true
+		case n == 21 || n == 24:
+			attr.underline = false
+		case n == 22:
+			attr.bold, attr.faint = false, false
+		case n == 23:
+			attr.italic = false
+		case n == 25:
+			attr.blink = false
+		case n == 27:
+			attr.inverse = false
+		case n == 28:
+			attr.hidden = false
+		case n == 29:
+			attr.strike = false
+		case n >= 30 && n <= 37:
+			attr.fg = color(n - 30)
+		case n == 38:
+			attr.fg, i = extendedColor(params, i)
+		case n == 39:
+			attr.fg = defaultColor
+		case n >= 40 && n <= 47:
+			attr.bg = color(n - 40)
+		case n == 48:
+			attr.bg, i = extendedColor(params, i)
+		case n == 49:
+			attr.bg = defaultColor
+		case n >= 90 && n <= 97:
+			attr.fg = color(n - 90 + 8)
+		case n >= 100 && n <= 107:
+			attr.bg = color(n - 100 + 8)
+		}
+	}
+}
+
+// extendedColor parses "5;n" (palette) or "2;r;g;b" following param #i (38 or 48)
+func extendedColor(params []string, i int) (color, int) {
+	value := func(j int) int {
+		if j >= len(params) || params[j] == "" {
+			return 0
+		}
+		return min(255, number(params[j]))
+	}
+	switch value(i + 1) {
+	case 5:
+		return color(value(i + 2)), i + 2
+	case 2:
+		return rgbColor | color(value(i+2)<<16|value(i+3)<<8|value(i+4)), i + 4
+	}
+	return defaultColor, len(params)
+}
+
+// rgb returns red, green and blue of color c, or of def if c is default
+func (c color) rgb(def color) (uint8, uint8, uint8) {
+	if c == defaultColor {
+		c = def
+	}
+	switch {
+	case c&rgbColor != 0:
+	case c < 16:
+		c = palette16[c]
+	case c < 232: // 6x6x6 cube
+		level := func(i color) color {
+			if i == 0 {
+				return 0
+			}
+			return 55 + i*40
+		}
+		i := c - 16
+		c = level(i/36)<<16 | level(i/6%6)<<8 | level(i%6)
+	default: // grayscale
+		gray := 8 + (c-232)*10
+		c = gray<<16 | gray<<8 | gray
+	}
+	return uint8(c >> 16), uint8(c >> 8), uint8(c)
+}
+
+func (c color) hex(def color) string {
+	r, g, b := c.rgb(def)
+	return fmt.Sprintf("#%02x%02x%02x", r, g, b)
+}
+
+// colors returns foreground and background, swapped if inverse
+func (attr attributes) colors() (color, color) {
+	fg, bg := attr.fg, attr.bg
+	if attr.inverse {
+		if fg == defaultColor {
+			fg = defaultForeground
+		}
+		if bg == defaultColor {
+			bg = defaultBackground
+		}
+		fg, bg = bg, fg
+	}
+	return fg, bg
+}
+
+// run is text with the same style and hyperlink
+type run struct {
+	attr   attributes
+	uri    string
+	linkID string
+	text   string
+}
+
+// runs splits a line (as returned by Capture) into text with the same style
+func runs(line string) []run {
+	result := []run{}
+	attr := newAttributes()
+	linkID, uri := "", ""
+	for len(line) > 0 {
+		loc := allAnsiCodes.FindStringIndex(line)
+		text := line
+		if loc != nil {
+			text = line[:loc[0]]
+		}
+		if len(text) > 0 {
+			if n := len(result); n > 0 && result[n-1].attr == attr && result[n-1].uri == uri && result[n-1].linkID == linkID {
+				result[n-1].text += text
+			} else {
+				result = append(result, run{attr: attr, uri: uri, linkID: linkID, text: text})
+			}
+		}
+		if loc == nil {
+			break
+		}
+		if code := line[loc[0]:loc[1]]; ansiStyleCodes.MatchString(code) {
+			attr.apply(code)
+		} else if ansiLinkCodes.MatchString(code) {
+			linkID, uri = parseLink(code)
+		}
+		line = line[loc[1]:]
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"testing"
+)
+
+func TestAttributes(t *testing.T) {
+	attr := newAttributes()
+	attr.apply("\x1b[1;4;31;42m")
+
+	assertTrue(t, attr.bold && attr.underline)
+	assertEquals(t, 1, int(attr.fg))
+	assertEquals(t, 2, int(attr.bg))
+	attr.apply("\x1b[22;39m")
+	assertTrue(t, !attr.bold && attr.underline)
+	assertEquals(t, int(defaultColor), int(attr.fg))
+	attr.apply("\x1b[m")
+	assertTrue(t, attr == newAttributes())
+}
+
+func TestAttributesExtendedColor(t *testing.T) {
+	attr := newAttributes()
+	attr.apply("\x1b[38;5;196;48;2;1;2;3;1m")
+
+	assertEqualsStr(t, "#ff0000", attr.fg.hex(defaultForeground))
+	assertEqualsStr(t, "#010203", attr.bg.hex(defaultBackground))
+	assertTrue(t, attr.bold)
+}
+
+func TestColorHex(t *testing.T) {
+	assertEqualsStr(t, "#cd0000", color(1).hex(defaultForeground))
+	assertEqualsStr(t, "#5f87af", color(67).hex(defaultForeground))
+	assertEqualsStr(t, "#eeeeee", color(255).hex(defaultForeground))
+	assertEqualsStr(t, "#e5e5e5", defaultColor.hex(defaultForeground))
+}
+
+func TestRuns(t *testing.T) {
+	got := runs("one \x1b[1mtwo\x1b[m\x1b[m three")
+
+	assertEquals(t, 3, len(got))
+	assertEqualsStr(t, "two", got[1].text)
+	assertTrue(t, got[1].attr.bold)
+	assertEqualsStr(t, " three", got[2].text)
+}
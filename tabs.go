@@ -0,0 +1,73 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+// WithTabInterval sets the columns between the initial tab stops (default 8). 0 means none.
+func WithTabInterval(columns int) Option {
+	return func(o *opt) {
+		o.tabInterval = max(0, columns)
+	}
+}
+
+// tabStop returns whether column x is a tab stop
+func (terminal *Terminal) tabStop(x int) bool {
+	if set, found := terminal.tabs[x]; found {
+		return set
+	}
+	return terminal.tabInterval > 0 && x > 0 && x%terminal.tabInterval == 0
+}
+
+// setTabStop sets (HTS) or clears (TBC) the tab stop at column x
+func (terminal *Terminal) setTabStop(x int, set bool) {
+	if terminal.tabs == nil {
+		terminal.tabs = map[int]bool{}
+	}
+	terminal.tabs[x] = set
+}
+
+// clearTabStops clears all tab stops (TBC 3)
+func (terminal *Terminal) clearTabStops() {
+	terminal.tabs = nil
+	terminal.tabInterval = 0
+}
+
+// nextTabStop returns the column of the count'th tab stop after x, or the last column
+func (terminal *Terminal) nextTabStop(x, count int) int {
+	width := terminal.opt.width
+	last := 0 // last explicit tab stop
+	for column, set := range terminal.tabs {
+		if set {
+			last = max(last, column)
+		}
+	}
+	for ; count > 0; count-- {
+		next := x + 1
+		for !terminal.tabStop(next) && (width == 0 || next < width-1) {
+			if width == 0 && terminal.tabInterval == 0 && next > last {
+				return x // no more tab stops
+			}
+			next++
+		}
+		if width > 0 && next >= width {
+			return width - 1
+		}
+		x = next
+	}
+	return x
+}
+
+// previousTabStop returns the column of the count'th tab stop before x, or the first column
+func (terminal *Terminal) previousTabStop(x, count int) int {
+	for ; count > 0 && x > 0; count-- {
+		x--
+		for x > 0 && !terminal.tabStop(x) {
+			x--
+		}
+	}
+	return x
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTabs(t *testing.T) {
+	input := "a\tb\tc\n" +
+		"=== RUN\tTest\n" +
+		"12345678\tx\n" +
+		"trailing\t\n"
+
+	assertEqualsStr(t, "a       b       c,=== RUN Test,12345678        x,trailing",
+		strings.Join(Capture(strings.NewReader(input)), ","))
+}
+
+func TestTabInterval(t *testing.T) {
+	lines := Capture(strings.NewReader("a\tb\tc"), WithTabInterval(4))
+
+	assertEqualsStr(t, "a   b   c", strings.Join(lines, ","))
+}
+
+func TestTabStops(t *testing.T) {
+	input := "\x1b[3g" + // clear all
+		"\x1b[3C\x1bH\x1b[3C\x1bH\r" + // set at 3 and 6
+		"\ta\tb\tc\n" +
+		"\x1b[2Ix\x1b[2Zy\x1b[5Zz\n" +
+		"\x1b[7G\x1b[g\r\ta\tb" // clear at 6
+
+	assertEqualsStr(t, "   a  bc,z  y  x,   ab", strings.Join(Capture(strings.NewReader(input)), ","))
+}
+
+func TestTabLastColumn(t *testing.T) {
+	terminal := NewTerminal(WithSize(10, 2))
+	terminal.Write([]byte("a\tb\tc\td"))
+
+	assertEqualsStr(t, "a       bd", strings.Join(terminal.Lines(), ","))
+}
@@ -11,15 +11,21 @@ import (
 	"bufio"
 	"fmt"
 	"io"
+	"net/url"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
-var allAnsiCodes = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]")
+var allAnsiCodes = regexp.MustCompile("\x1b\\[[0-9;]*[A-Za-z]|\x1b\\]8;[^\x07\x1b]*\x1b\\\\|\x1b#[0-9]")
 var ansiStyleCodes = regexp.MustCompile("\x1b\\[[0-9;]*m")
 var ansiResetCode = regexp.MustCompile("\x1b\\[([0-9;]*;[0;]*)?[0;]*m")
+var ansiControlCodes = regexp.MustCompile("\x1b\\[([?>=<]?)([0-9;]*)([ -/]*)([@-~])|" +
+	"\x1b([]P_^X])([^\x07\x1b]*)(?:\x07|\x1b\\\\)|" +
+	"\x1b([ -/]*)([0-OQ-WYZ\\\\`-~])|[\t\n\r\x07\x0b\x0c\x0e\x0f]") // CSI, OSC/DCS/APC/PM/SOS, other escape, or control
+var incompleteCode = regexp.MustCompile("\x1b(\\[[?>=<]?[0-9;]*[ -/]*|[]P_^X][^\x07\x1b]*\x1b?|[ -/]+)?$")
 
 type stringReader interface {
 	// ReadString reads until the first occurrence of delim in the input,
@@ -32,7 +38,7 @@ type stringReader interface {
 }
 
 // Capture reads ANSI escape codes and normalizes the printed text
-func Capture(reader io.Reader, opts ...option) []string {
+func Capture(reader io.Reader, opts ...Option) []string {
 	var bufioReader *bufio.Reader = bufio.NewReader(reader)
 	var strReader stringReader = bufioReader
 	lines := captureStringReader(strReader, opts...)
@@ -40,32 +46,183 @@ func Capture(reader io.Reader, opts ...option) []string {
 }
 
 type opt struct {
-	stripStyling bool
+	stripStyling  bool
+	width, height int
+	responses     io.Writer
+	primaryDA     string
+	secondaryDA   string
+	noBCE         bool
+	profile       *Profile
+	reflow        bool
+	joinWrapped   bool
+	rawLineFeed   bool
+	unknown       func(seq Sequence)
+	handlers      map[string]Handler // custom handlers, by introducer and sequence, number or prefix
+	trace         func(step Step)
+	sourceMap     bool
+	tabInterval   int
 }
-type option func(o *opt)
 
-func StripStyling() option {
+// Option changes how output is interpreted or normalized
+type Option func(o *opt)
+
+func StripStyling() Option {
 	return func(o *opt) {
 		o.stripStyling = true
 	}
 }
 
-type terminal struct {
-	screen []string
-	x, y   int
-	style  string
+// WithSize sets the number of columns and rows of the screen. Text wraps at the last column, and
+// the screen scrolls when the cursor moves below the last row. 0 means unlimited (the default).
+func WithSize(columns, rows int) Option {
+	return func(o *opt) {
+		o.width = max(0, columns)
+		o.height = max(0, rows)
+	}
+}
+
+// WithResponseWriter makes the terminal answer queries, like cursor position and device attributes,
+// by writing to responses (e.g. the input of the program being captured). Without it, queries are
+// ignored.
+func WithResponseWriter(responses io.Writer) Option {
+	return func(o *opt) {
+		o.responses = responses
+	}
+}
+
+// WithDeviceAttributes sets the answers to primary ("\x1b[c") and secondary ("\x1b[>c") device
+// attributes queries, which identify the terminal. The default is a VT220 with colors.
+func WithDeviceAttributes(primary, secondary string) Option {
+	return func(o *opt) {
+		o.primaryDA = primary
+		o.secondaryDA = secondary
+	}
+}
+
+// Terminal interprets the output written to it, like Capture, but the screen
+// can be inspected at any time, e.g. while a program is still running
+type Terminal struct {
+	screen      []string
+	x, y        int
+	style       string
+	link        string // hyperlink code, if inside a hyperlink
+	top         int    // first row of screen, rows above have scrolled out (only with height)
+	opt         opt
+	pending     string    // incomplete escape code or character at end of last Write
+	offset      int       // number of bytes handled, not counting pending
+	sequence    Sequence  // being handled
+	ignored     bool      // the sequence being handled is not supported
+	writes      []written // times of output, for the source map
+	modes       map[Mode]bool
+	onFrame     func() // called when the screen may be complete: before clear and around synchronized update
+	err         error  // from writing responses
+	title       string
+	iconName    string
+	titles      [][2]string // stack of title and icon name
+	directory   string
+	marks       []mark // shell integration
+	effects     []Effect
+	charsets    [4]string // designated G0-G3, "" is ASCII
+	gl          int       // charset invoked into GL
+	margins     margins
+	lineSizes   map[int]byte // rows which are not single-width, by line size code
+	wrapped     map[int]bool // rows which continue on the next row, as text wrapped at the last column
+	protected   bool         // characters printed are protected from selective erase
+	newLineMode bool         // LNM, line feeds also return the cursor
+	saved       *cursor
+	tabs        map[int]bool // tab stops set or cleared, overriding every tabInterval column
+	tabInterval int
+	singleShift int                  // charset used for the next character only, or 0
+	elapsed     func() time.Duration // time since start, for effects
+}
+
+// NewTerminal returns a Terminal with an empty screen
+func NewTerminal(opts ...Option) *Terminal {
+	terminal := &Terminal{screen: make([]string, 0), x: 0, y: 0, style: ""}
+	terminal.modes = map[Mode]bool{AutoWrap: true, CursorVisible: true}
+	start := time.Now()
+	terminal.elapsed = func() time.Duration { return time.Since(start) }
+	terminal.opt.primaryDA = "\x1b[?62;22c"
+	terminal.opt.secondaryDA = "\x1b[>1;0;0c"
+	terminal.opt.tabInterval = 8
+	for _, op := range opts {
+		op(&terminal.opt)
+	}
+	terminal.tabInterval = terminal.opt.tabInterval
+	return terminal
+}
+
+// Write interprets output. Escape codes and characters split between writes are held back until
+// they are complete. An error is returned if answering a query failed.
+func (terminal *Terminal) Write(p []byte) (int, error) {
+	terminal.write(terminal.offset + len(terminal.pending))
+	text := terminal.pending + string(p)
+	terminal.pending = "" // a handler may write, before this returns
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			break
+		}
+		terminal.handleText(text[:i+1])
+		text = text[i+1:]
+	}
+	i := incomplete(text)
+	if i > 0 {
+		terminal.handleText(text[:i])
+	}
+	terminal.pending = text[i:]
+	err := terminal.err
+	terminal.err = nil
+	return len(p), err
+}
+
+// Lines returns all lines, including those scrolled out of the screen, normalized like Capture does
+func (terminal *Terminal) Lines() []string {
+	return terminal.normalize(terminal.joined(0))
+}
+
+// Screen returns the lines on screen. Without a row limit, this is the same as Lines.
+func (terminal *Terminal) Screen() []string {
+	return terminal.normalize(terminal.joined(min(terminal.top, len(terminal.screen))))
+}
+
+func (terminal *Terminal) normalize(lines []string) []string {
+	lines = withoutSources(withoutProtection(lines))
+	if terminal.opt.stripStyling {
+		return stripStyles(lines)
+	} else {
+		return cleanStyles(lines)
+	}
+}
+
+// incomplete returns index of an unfinished escape code or UTF-8 sequence at the end of text,
+// or len(text) if there is none
+func incomplete(text string) int {
+	if loc := incompleteCode.FindStringIndex(text); loc != nil {
+		return loc[0]
+	}
+	return incompleteRune(text)
+}
+
+// incompleteRune returns index of an unfinished UTF-8 sequence at the end of text, or len(text)
+func incompleteRune(text string) int {
+	for i := len(text) - 1; i >= 0 && i >= len(text)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(text[i]) {
+			if !utf8.FullRuneInString(text[i:]) {
+				return i
+			}
+			break
+		}
+	}
+	return len(text)
 }
 
-func captureStringReader(reader stringReader, opts ...option) []string {
-	terminal := &terminal{screen: make([]string, 0), x: 0, y: 0, style: ""}
-	ansiControlCodes := regexp.MustCompile("\x1b\\[([0-9]*)([ABCDEFGJK]|;?[0-9]*H)")
+func captureStringReader(reader stringReader, opts ...Option) []string {
+	terminal := NewTerminal(opts...)
 	for {
 		line, err := reader.ReadString('\n')
 		if err == nil || (err == io.EOF && len(line) > 0) {
-			if len(line) > 0 && line[len(line)-1:] == "\n" {
-				line = line[:len(line)-1]
-			}
-			terminal.handleLine(ansiControlCodes, line)
+			terminal.handleLine(line)
 		}
 		if err != nil && err != io.EOF {
 			panic(fmt.Sprintf("Error %s", err))
@@ -74,124 +231,525 @@ func captureStringReader(reader stringReader, opts ...option) []string {
 			break
 		}
 	}
-	o := opt{}
-	for _, op := range opts {
-		op(&o)
+	return terminal.Lines()
+}
+
+func (terminal *Terminal) handleLine(line string) {
+	terminal.write(terminal.offset)
+	terminal.handleText(line)
+}
+
+// lineFeed moves cursor down, scrolling if at the bottom of the screen
+func (terminal *Terminal) lineFeed() {
+	if terminal.hasMargins() {
+		if _, bottom, _, _ := terminal.region(); terminal.y == bottom && terminal.inRegion() {
+			terminal.scroll(1)
+			return
+		}
+		if terminal.y == terminal.top+terminal.opt.height-1 { // below scroll region
+			return
+		}
 	}
-	if o.stripStyling {
-		return stripStyles(terminal.screen)
-	} else {
-		return cleanStyles(terminal.screen)
+	terminal.y += 1
+	if height := terminal.opt.height; height > 0 && terminal.y >= terminal.top+height {
+		terminal.top = terminal.y - height + 1
+		if bg := terminal.erasedBackground(); bg != "" && terminal.y >= len(terminal.screen) {
+			terminal.screen = print(terminal.screen, colored(bg, terminal.columns(terminal.y)), 0, terminal.y)
+		}
+	}
+}
+
+// handleString handles OSC ("]") and other strings terminated by ST (or BEL)
+func (terminal *Terminal) handleString(introducer, data string) {
+	if introducer != "]" {
+		terminal.unsupported()
+		return
+	}
+	command, arg, _ := strings.Cut(data, ";")
+	switch command {
+	case "0": // Icon name and title
+		terminal.title = arg
+		terminal.iconName = arg
+	case "1":
+		terminal.iconName = arg
+	case "2":
+		terminal.title = arg
+	case "7": // Working directory, file://host/path
+		if location, err := url.Parse(arg); err == nil && location.Scheme == "file" {
+			terminal.directory = location.Path
+		}
+	case "9": // Notification (iTerm2)
+		terminal.notify("", arg)
+	case "777": // Notification (rxvt)
+		if args := strings.SplitN(arg, ";", 3); args[0] == "notify" && len(args) == 3 {
+			terminal.notify(args[1], args[2])
+		}
+	case "52": // Clipboard
+		terminal.copy(arg)
+	case "133": // Shell integration
+		terminal.handleMark(arg)
+	case "1337": // iTerm2
+		if directory, found := strings.CutPrefix(arg, "CurrentDir="); found {
+			terminal.directory = directory
+		}
+	default:
+		terminal.unsupported()
+	}
+}
+
+// Title returns the window title set by the program
+func (terminal *Terminal) Title() string {
+	return terminal.title
+}
+
+// IconName returns the icon name set by the program
+func (terminal *Terminal) IconName() string {
+	return terminal.iconName
+}
+
+// WorkingDirectory returns the current directory reported by the program (e.g. a shell)
+func (terminal *Terminal) WorkingDirectory() string {
+	return terminal.directory
+}
+
+// Cursor returns the column and row of the cursor on screen, 0-based
+func (terminal *Terminal) Cursor() (int, int) {
+	x, y := terminal.limit(terminal.x, terminal.y)
+	return x, y - terminal.top
+}
+
+// respond writes answer to a query, if there is a response writer
+func (terminal *Terminal) respond(answer string) {
+	if terminal.opt.responses == nil || answer == "" {
+		return
+	}
+	if _, err := io.WriteString(terminal.opt.responses, answer); err != nil && terminal.err == nil {
+		terminal.err = err
 	}
 }
 
-func (terminal *terminal) handleLine(ansiControlCodes *regexp.Regexp, line string) {
-	terminal.x = 0
-	text := line
+// cursorReport returns cursor row and column, 1-based, as reported to programs
+func (terminal *Terminal) cursorReport() (int, int) {
+	x, y := terminal.limit(terminal.x, terminal.y)
+	left, top := terminal.home()
+	return y - top + 1, x - left + 1
+}
+
+// size returns columns and rows reported to programs, with unlimited size reported as 80x24
+func (terminal *Terminal) size() (int, int) {
+	columns, rows := terminal.opt.width, terminal.opt.height
+	if columns == 0 {
+		columns = 80
+	}
+	if rows == 0 {
+		rows = 24
+	}
+	return columns, rows
+}
+
+// limit keeps cursor on screen
+func (terminal *Terminal) limit(x, y int) (int, int) {
+	x, y = max(0, x), max(terminal.top, y)
+	if height := terminal.opt.height; height > 0 {
+		y = min(y, terminal.top+height-1)
+	}
+	if width := terminal.lineWidth(y); width > 0 {
+		x = min(x, width-1)
+	}
+	if terminal.modes[OriginMode] && terminal.hasMargins() {
+		top, bottom, left, right := terminal.region()
+		x, y = min(max(x, left), right), min(max(y, top), bottom)
+	}
+	return x, y
+}
+
+// sequence is a parsed control sequence, e.g. "\x1b[?25l" has prefix "?", params "25" and final "l"
+type sequence struct {
+	prefix, params, intermediate, final string
+}
+
+// param returns parameter #i (0-based), or def if it is missing
+func (seq sequence) param(i int, def int) int {
+	params := strings.Split(seq.params, ";")
+	if i >= len(params) || params[i] == "" {
+		return def
+	}
+	return number(params[i])
+}
+
+func (seq sequence) hasParam(i int) bool {
+	return seq.param(i, -1) != -1
+}
+
+func (terminal *Terminal) handleText(text string) {
+	newLine := strings.HasSuffix(text, "\n") // the next line is not begun until printed to
+	printable := ""
 	for {
 		indices := ansiControlCodes.FindStringSubmatchIndex(text)
-		printable := text
-		if indices != nil && len(indices) > 4 {
-			printable = text[:indices[0]]
-		}
-		terminal.printTerm(printable)
-		if indices != nil && len(indices) > 4 {
-			countStart := indices[2]
-			countEnd := indices[3]
-			codeStart := indices[4]
-			codeEnd := indices[5]
-			codes := text[codeStart:codeEnd]
-			code := codes[:1]
-			count := 1
-			if countEnd > countStart {
-				count = number(text[countStart:countEnd])
-			}
-			terminal.handleCode(countStart, countEnd, codeStart, codeEnd, count, codes, code)
-			if len(text) > indices[1] {
-				text = text[indices[1]:]
-			} else {
-				break
-			}
-		} else {
+		if indices == nil {
 			break
 		}
+		seq := parseSequence(text, indices, terminal.offset)
+		plain := terminal.sourced(text[:indices[0]], terminal.offset)
+		terminal.offset += indices[1] // before handling, as a handler may write
+		handler := terminal.handler(seq)
+		code := seq.Text
+		if indices[10] >= 0 { // String, terminated by ST rather than BEL
+			code = "\x1b" + seq.Introducer + text[indices[12]:indices[13]] + "\x1b\\"
+		}
+		if handler == nil && (ansiStyleCodes.MatchString(code) || ansiLinkCodes.MatchString(code)) {
+			// styles and hyperlinks are printed along with the text
+			printable += plain + code
+			at := terminal.cursorAfter(printable)
+			terminal.traceStep(seq, false, at, at)
+			text = text[indices[1]:]
+			continue
+		}
+		terminal.printTerm(printable + plain)
+		printable = ""
+		terminal.sequence, terminal.ignored = seq, false
+		before := terminal.cursorAfter("")
+		if handler != nil {
+			handler(terminal, seq)
+		} else if seq.Text[0] != '\x1b' { // Control character
+			terminal.handleControl(seq.Text[0])
+		} else if indices[16] >= 0 { // Escape sequence, not CSI or string
+			terminal.handleEscape(seq.Intermediate, seq.Final)
+		} else if indices[10] >= 0 { // String
+			terminal.handleString(seq.Introducer, text[indices[12]:indices[13]])
+		} else {
+			terminal.handleCode(sequence{prefix: seq.Prefix, params: seq.Params,
+				intermediate: seq.Intermediate, final: seq.Final})
+		}
+		terminal.traceStep(seq, terminal.ignored, before, terminal.cursorAfter(""))
+		text = text[indices[1]:]
+	}
+	if printable+text != "" || !newLine {
+		terminal.printTerm(printable + terminal.sourced(text, terminal.offset))
+	}
+	terminal.offset += len(text)
+}
+
+func (terminal *Terminal) handleControl(c byte) {
+	switch c {
+	case '\t': // Tab
+		x, y := terminal.limit(terminal.x, terminal.y)
+		terminal.x, terminal.y = terminal.nextTabStop(x, 1), y
+	case '\n': // Line feed
+		terminal.newLine()
+	case '\r': // Carriage return
+		terminal.x = 0
+	case '\x0b', '\x0c': // Vertical tab, form feed
+		terminal.verticalFeed()
+	case '\a': // Bell
+		terminal.effect(Effect{Kind: Bell})
+	case '\x0e': // Shift out, G1 to GL
+		terminal.gl = 1
+	case '\x0f': // Shift in, G0 to GL
+		terminal.gl = 0
+	}
+}
+
+// handleEscape handles an escape sequence with optional intermediate characters, e.g. "\x1b(0"
+func (terminal *Terminal) handleEscape(intermediate, final string) {
+	switch {
+	case intermediate == "" && final == "H": // Set tab stop
+		x, _ := terminal.limit(terminal.x, terminal.y)
+		terminal.setTabStop(x, true)
+	case intermediate == "" && final == "7": // Save cursor
+		terminal.saveCursor()
+	case intermediate == "" && final == "8": // Restore cursor
+		terminal.restoreCursor()
+	case intermediate == "" && final == "D": // Index
+		terminal.lineFeed()
+	case intermediate == "" && final == "E": // Next line
+		terminal.lineFeed()
+		terminal.x = terminal.margins.left
+	case intermediate == "" && final == "M": // Reverse index
+		terminal.reverseIndex()
+	case intermediate == "#" && strings.Contains("3456", final): // Line size
+		terminal.setLineSize(final[0])
+	case intermediate == "#" && final == "8": // Screen alignment test
+		terminal.alignmentTest()
+	case intermediate == "" && final == "n": // Locking shift 2, G2 to GL
+		terminal.gl = 2
+	case intermediate == "" && final == "o": // Locking shift 3, G3 to GL
+		terminal.gl = 3
+	case intermediate == "" && final == "N": // Single shift 2
+		terminal.singleShift = 2
+	case intermediate == "" && final == "O": // Single shift 3
+		terminal.singleShift = 3
+	case intermediate != "" && strings.IndexByte("()*+", intermediate[0]) >= 0: // Designate G0-G3
+		terminal.charsets[strings.IndexByte("()*+", intermediate[0])] = intermediate[1:] + final
+	default:
+		terminal.unsupported()
 	}
-	terminal.y += 1
 }
 
-func (terminal *terminal) handleCode(countStart, countEnd, codeStart, codeEnd, count int, codes, code string) {
+func (terminal *Terminal) handleCode(seq sequence) {
 	screen := terminal.screen
 	x, y := terminal.x, terminal.y
-	switch code {
+	top := terminal.top
+	count := seq.param(0, 1)
+	switch seq.prefix + seq.intermediate + seq.final {
 	case "A": // Up
-		y = max(0, y-count)
+		x, y = terminal.limit(x, y-count)
 	case "B": // Down
-		y += count
+		x, y = terminal.limit(x, y+count)
 	case "C": // Forward
-		x += count
+		x, y = terminal.limit(x+count, y)
 	case "D": // Back
-		x = max(0, x-count)
+		x, y = terminal.limit(x, y) // may be past last column, waiting to wrap
+		x, y = terminal.limit(x-count, y)
 	case "E": // Next line
-		y += count
-		x = 0
+		x, y = terminal.limit(0, y+count)
 	case "F": // Previous line
-		y -= count
-		x = 0
+		x, y = terminal.limit(0, y-count)
 	case "G": // Column
-		if countEnd == countStart {
+		if !seq.hasParam(0) {
 			x = 1
 		} else {
-			x = max(0, count-1)
+			x, y = terminal.limit(count-1, y)
 		}
+	case "I": // Forward tab stops
+		x, y = terminal.limit(x, y)
+		x = terminal.nextTabStop(x, count)
+	case "Z": // Back tab stops
+		x, y = terminal.limit(x, y)
+		x = terminal.previousTabStop(x, count)
+	case "g": // Clear tab stops
+		if mode := seq.param(0, 0); mode == 0 {
+			x, y = terminal.limit(x, y)
+			terminal.setTabStop(x, false)
+		} else if mode == 3 {
+			terminal.clearTabStops()
+		}
+	case "H": // Position
+		left, top := terminal.home()
+		x, y = terminal.limit(left+seq.param(1, 1)-1, top+count-1)
 	case "J": // Erase in Display
+		count = seq.param(0, 0)
+		if profile := terminal.opt.profile; count == 3 && profile != nil {
+			if profile.eraseScrollback {
+				terminal.eraseScrollback()
+				screen, y = terminal.screen, terminal.y
+			}
+			break
+		}
 		idx := pos(screen[y], x)
-		if count == 0 || countEnd == countStart { // To end
+		last := terminal.lastRow()
+		if count == 0 { // To end
 			if length(screen[y]) > x {
 				screen[y] = screen[y][0:idx]
 			}
 			screen = screen[0 : y+1]
+			terminal.clearRows(y+1, len(terminal.screen))
 		} else if count == 1 { // To begining
 			screen[y] = strings.Repeat(" ", x) + screen[y][idx:]
-			for idx := range screen[0:y] {
+			for idx := top; idx < y; idx++ {
 				screen[idx] = ""
 			}
+			terminal.clearRows(top, y)
 		} else if count > 1 { // All
-			screen = screen[:0]
+			if terminal.onFrame != nil && !terminal.modes[SynchronizedOutput] {
+				terminal.onFrame()
+			}
+			screen = screen[:top]
+			terminal.clearRows(top, len(terminal.screen))
 			x = 0
-			y = 0
+			y = top
+			marks := []mark{}
+			for _, m := range terminal.marks {
+				if m.y < top {
+					marks = append(marks, m)
+				}
+			}
+			terminal.marks = marks
 		}
+		terminal.screen, terminal.x, terminal.y = screen, x, y
+		terminal.colorErased(true, count, last)
+		screen = terminal.screen
 	case "K": // Erase in Line
+		count = seq.param(0, 0)
 		idx := pos(screen[y], x)
-		if count == 0 || countEnd == countStart { // To end
+		if count == 0 { // To end
 			screen[y] = screen[y][0:idx]
+			delete(terminal.wrapped, y)
 		} else if count == 1 { // To beginning
-			screen[y] = strings.Repeat(" ", x) + screen[y][idx:]
+			screen[y] = strings.Repeat(" ", x) + openLink(screen[y][:idx]) + screen[y][idx:]
 		} else if count == 2 { // All
 			screen[y] = ""
+			delete(terminal.wrapped, y)
 		}
-	default:
-		if codes[len(codes)-1:] == "H" { // Position
-			y = max(0, count-1)
-			if codes[0:1] == ";" {
-				codes = codes[1:]
+		terminal.screen = screen
+		terminal.colorErased(false, count, y+1)
+		screen = terminal.screen
+	case "X": // Erase characters
+		x, y = terminal.limit(x, y)
+		count = min(count, terminal.columns(y)-x)
+		if bg := terminal.erasedBackground(); bg != "" {
+			screen = print(screen, colored(bg, count), x, y)
+		} else {
+			screen[y] = replaceColumns(screen[y], x, x+count, "")
+		}
+	case "n": // Device status report
+		if count == 5 {
+			terminal.respond("\x1b[0n")
+		} else if count == 6 {
+			row, column := terminal.cursorReport()
+			terminal.respond(fmt.Sprintf("\x1b[%d;%dR", row, column))
+		}
+	case "?n":
+		if count == 6 {
+			row, column := terminal.cursorReport()
+			terminal.respond(fmt.Sprintf("\x1b[?%d;%dR", row, column))
+		}
+	case "c": // Device attributes
+		if seq.param(0, 0) == 0 {
+			terminal.respond(terminal.opt.primaryDA)
+		}
+	case ">c":
+		if seq.param(0, 0) == 0 {
+			terminal.respond(terminal.opt.secondaryDA)
+		}
+	case "t": // Window operations
+		columns, rows := terminal.size()
+		if count == 18 { // Text area size
+			terminal.respond(fmt.Sprintf("\x1b[8;%d;%dt", rows, columns))
+		} else if count == 19 { // Screen size
+			terminal.respond(fmt.Sprintf("\x1b[9;%d;%dt", rows, columns))
+		} else if count == 8 { // Resize
+			columns, rows = seq.param(2, 0), seq.param(1, 0)
+			if columns == 0 {
+				columns = terminal.opt.width
 			}
-			if len(codes) > 1 {
-				x = max(0, number(codes[0:len(codes)-1])-1)
-			} else {
-				x = 0
+			if rows == 0 {
+				rows = terminal.opt.height
+			}
+			terminal.Resize(columns, rows)
+			screen, x, y = terminal.screen, terminal.x, terminal.y
+		} else if count == 22 { // Push title
+			terminal.titles = append(terminal.titles, [2]string{terminal.title, terminal.iconName})
+		} else if count == 23 && len(terminal.titles) > 0 { // Pop title
+			saved := terminal.titles[len(terminal.titles)-1]
+			terminal.titles = terminal.titles[:len(terminal.titles)-1]
+			which := seq.param(1, 0)
+			if which == 0 || which == 2 {
+				terminal.title = saved[0]
 			}
+			if which == 0 || which == 1 {
+				terminal.iconName = saved[1]
+			}
+		}
+	case "r": // Set top and bottom margins
+		terminal.setVerticalMargins(seq)
+		x, y = terminal.x, terminal.y
+	case "s":
+		if terminal.modes[LeftRightMargins] { // Set left and right margins
+			terminal.setHorizontalMargins(seq)
+			x, y = terminal.x, terminal.y
+		} else { // Save cursor
+			terminal.saveCursor()
 		}
+	case "u": // Restore cursor
+		terminal.restoreCursor()
+		x, y = terminal.x, terminal.y
+	case "S": // Scroll up
+		terminal.scroll(count)
+		screen = terminal.screen
+	case "T": // Scroll down
+		terminal.scroll(-count)
+		screen = terminal.screen
+	case "$x": // Fill rectangular area
+		terminal.fillRectangle(seq)
+		screen = terminal.screen
+	case "$z": // Erase rectangular area
+		terminal.eraseRectangle(seq)
+		screen = terminal.screen
+	case "${": // Selective erase rectangular area
+		terminal.selectiveEraseRectangle(seq)
+		screen = terminal.screen
+	case "$v": // Copy rectangular area
+		terminal.copyRectangle(seq)
+		screen = terminal.screen
+	case "\"q": // Select character protection attribute
+		terminal.protected = seq.param(0, 0) == 1
+	case "?J": // Selective erase in display
+		terminal.selectiveErase(seq, true)
+		screen = terminal.screen
+	case "?K": // Selective erase in line
+		terminal.selectiveErase(seq, false)
+		screen = terminal.screen
+	case "?u": // Keyboard protocol flags (none supported)
+		terminal.respond("\x1b[?0u")
+	case "h", "l": // Set/reset mode
+		terminal.setANSIMode(seq)
+	case "$p": // Request ANSI mode
+		terminal.reportANSIMode(seq.param(0, 0))
+	case "?h", "?l": // Set/reset private mode
+		terminal.setMode(seq)
+	case "?$p": // Request mode
+		terminal.reportMode(Mode(seq.param(0, 0)))
+	default:
+		terminal.unsupported()
 	}
 	terminal.x = x
 	terminal.y = y
 	terminal.screen = screen
 }
 
-func (terminal *terminal) printTerm(text string) {
-	terminal.screen = print(terminal.screen, terminal.style+text, terminal.x, terminal.y)
+func (terminal *Terminal) printTerm(text string) {
+	text = terminal.translate(text)
+	if length(text) == 0 { // styles apply to text printed later, and the line is not padded
+		for terminal.y >= len(terminal.screen) {
+			terminal.screen = append(terminal.screen, "")
+		}
+		terminal.style = updateStyle(ansiStyleCodes.FindAllString(terminal.style+text, -1))
+		terminal.link = openLink(terminal.link + text)
+		return
+	}
+	width, left := terminal.lineWidth(terminal.y), 0
+	if terminal.hasMargins() && terminal.inRegion() {
+		_, _, left, width = terminal.region()
+		width++
+	}
+	for width > 0 && terminal.x+length(text) > width {
+		if terminal.x >= width && !terminal.modes[AutoWrap] { // overwrite last column
+			terminal.x = width - 1
+		} else if terminal.x >= width { // wrap
+			if left == 0 && width == terminal.opt.width {
+				terminal.setWrapped(terminal.y)
+			}
+			terminal.lineFeed()
+			terminal.x = left
+			continue
+		}
+		i := pos(text, width-terminal.x)
+		terminal.printAt(text[:i])
+		text = text[i:]
+	}
+	terminal.printAt(text)
+	if width > 0 && terminal.x >= width && !terminal.modes[AutoWrap] {
+		terminal.x = width - 1
+	}
+}
+
+// printAt prints text at cursor, without wrapping
+func (terminal *Terminal) printAt(text string) {
+	link := openLink(terminal.link + text)
+	end := ""
+	if link != "" {
+		end = linkEnd
+	}
+	printed := text
+	if terminal.protected {
+		printed = protect(text)
+	}
+	terminal.screen = print(terminal.screen, terminal.style+terminal.link+printed+end, terminal.x, terminal.y)
 	terminal.x += length(text)
 	styles := ansiStyleCodes.FindAllString(terminal.style+text, -1)
 	terminal.style = updateStyle(styles)
+	terminal.link = link
 }
 
 func print(screen []string, text string, x int, y int) []string {
@@ -199,18 +757,21 @@ func print(screen []string, text string, x int, y int) []string {
 		screen = append(screen, "")
 	}
 	if y < len(screen) {
-		prefix := ""
+		prefix := screen[y]
 		lineLen := length(screen[y])
 		if x < lineLen {
 			prefix = screen[y][0:pos(screen[y], x)]
-		} else {
-			prefix = screen[y] + strings.Repeat(" ", x-lineLen)
 		}
+		if openLink(prefix) != "" {
+			prefix += linkEnd
+		}
+		prefix += strings.Repeat(" ", max(0, x-lineLen))
 		suffix := ""
 		if lineLen > x+length(text) {
 			idx := pos(screen[y], max(0, min(x+1, lineLen-1)))
 			styles := updateStyle(ansiStyleCodes.FindAllString(screen[y][:idx], -1))
-			suffix = styles + screen[y][pos(screen[y], x+length(text)):]
+			end := pos(screen[y], x+length(text))
+			suffix = styles + openLink(screen[y][:end]) + screen[y][end:]
 		}
 		screen[y] = prefix + text + suffix
 	}
@@ -249,13 +810,11 @@ func pos(value string, i int) int {
 			columns++
 		}
 		offset += lenPassed
-		if columns >= i {
+		if columns >= i || pos == nil { // past the end, the cursor may be there without padding
 			return offset
 		}
-		if pos != nil {
-			value = value[pos[1]:]
-			offset += pos[1] - pos[0]
-		}
+		value = value[pos[1]:]
+		offset += pos[1] - pos[0]
 	}
 }
 
@@ -284,7 +843,7 @@ func cleanStyles(screen []string) []string {
 			i = end
 		}
 		line += row
-		lines = append(lines, line)
+		lines = append(lines, cleanLinks(line))
 	}
 	return lines
 }
@@ -10,6 +10,7 @@ package termscreen
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"testing"
@@ -234,6 +235,25 @@ func TestEraseInDisplayToBeginning(t *testing.T) {
 	assertEqualsStr(t, "\n     ", lines)
 }
 
+func TestEraseInLinePastEnd(t *testing.T) {
+	for str, want := range map[string]string{
+		"\x1b[1mab\x1b[m\x1b[5C\x1b[K\n": "\x1b[1mab\x1b[m",
+		"ab\x1b[5C\x1b[K\n":              "ab",
+		"main.go:\t\x1b[Kx\n":            "main.go:        x",
+	} {
+		lines := strings.Join(captureStringReader(strReader(str)), "\n")
+
+		assertEqualsStr(t, want, lines)
+	}
+}
+
+func TestEraseInDisplayPastEnd(t *testing.T) {
+	str := "c\x1b[m\x1b[C\x1b[J\n"
+	lines := strings.Join(captureStringReader(strReader(str)), "\n")
+
+	assertEqualsStr(t, "c\x1b[m", lines)
+}
+
 func TestLenEmpty(t *testing.T) {
 	assertEquals(t, 0, length(""))
 }
@@ -297,6 +317,12 @@ func TestPosComplex(t *testing.T) {
 	assertEquals(t, 23, pos(str, 12))
 }
 
+func TestPosPastEnd(t *testing.T) {
+	for _, str := range []string{"ab", "ab\x1b[m", "\x1b[1mab\x1b[m"} {
+		assertEquals(t, len(str), pos(str, 5))
+	}
+}
+
 func TestPosUnicode(t *testing.T) {
 	assertEquals(t, 3, pos("↑ ", 1))
 }
@@ -394,6 +420,135 @@ func TestResetCode(t *testing.T) {
 	assertResetCode(false, "\x1b[40;1m")
 }
 
+func TestTerminal(t *testing.T) {
+	terminal := NewTerminal()
+	for _, part := range []string{"hello, ", "earth!\x1b", "[7", "D world\n", "\x1b[31", "m\xe2\x86", "\x91"} {
+		terminal.Write([]byte(part))
+	}
+
+	got := strings.Join(terminal.Lines(), ",")
+	assertEqualsStr(t, "hello, world!,\x1b[31m↑", got)
+}
+
+func TestTerminalIncomplete(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte("one\x1b["))
+
+	assertEqualsStr(t, "one", strings.Join(terminal.Lines(), ","))
+	assertEqualsStr(t, "\x1b[", terminal.pending)
+}
+
+func TestPrivateModeHidden(t *testing.T) {
+	lines := captureStringReader(strReader("\x1b[?25lhello\x1b[?25h\n"))
+
+	assertEqualsStr(t, "hello", strings.Join(lines, ","))
+}
+
+func TestCarriageReturn(t *testing.T) {
+	lines := captureStringReader(strReader("50%\r100%\r\n"))
+
+	assertEqualsStr(t, "100%", strings.Join(lines, ","))
+}
+
+func TestSizeWrap(t *testing.T) {
+	lines := captureStringReader(strReader("\x1b[31mhello, world\n"), WithSize(5, 0))
+
+	want := "\x1b[31mhello,\x1b[31m, wor,\x1b[31mld"
+	assertEqualsStr(t, want, strings.Join(lines, ","))
+}
+
+func TestSizeWrapPending(t *testing.T) {
+	lines := captureStringReader(strReader("abc\x1b[Dd\x1b[1De\n"), WithSize(3, 0))
+
+	assertEqualsStr(t, "aec", strings.Join(lines, ","))
+}
+
+func TestSizeScroll(t *testing.T) {
+	terminal := NewTerminal(WithSize(5, 2))
+	terminal.Write([]byte("one\ntwo\nthree\x1b[1;1Hx\x1b[9;9Hy"))
+
+	assertEqualsStr(t, "xwo,threy", strings.Join(terminal.Screen(), ","))
+	assertEqualsStr(t, "one,xwo,threy", strings.Join(terminal.Lines(), ","))
+}
+
+func TestSizeClear(t *testing.T) {
+	terminal := NewTerminal(WithSize(5, 2))
+	terminal.Write([]byte("one\ntwo\nthree\x1b[2Jfour"))
+
+	assertEqualsStr(t, "four", strings.Join(terminal.Screen(), ","))
+	assertEqualsStr(t, "one,four", strings.Join(terminal.Lines(), ","))
+}
+
+func TestResponses(t *testing.T) {
+	var responses strings.Builder
+	terminal := NewTerminal(WithSize(10, 5), WithResponseWriter(&responses))
+	terminal.Write([]byte("\x1b[5nhello\x1b[6n\x1b[?6n\x1b[c\x1b[>c\x1b[18t\x1b[19t\x1b[?u\x1b[3;4H\x1b[6n"))
+
+	want := "\x1b[0n\x1b[1;6R\x1b[?1;6R\x1b[?62;22c\x1b[>1;0;0c\x1b[8;5;10t\x1b[9;5;10t\x1b[?0u\x1b[3;4R"
+	assertEqualsStr(t, want, responses.String())
+	assertEqualsStr(t, "hello", terminal.Lines()[0])
+}
+
+func TestResponsesScrolled(t *testing.T) {
+	var responses strings.Builder
+	terminal := NewTerminal(WithSize(3, 2), WithResponseWriter(&responses), WithDeviceAttributes("\x1b[?1;2c", ""))
+	terminal.Write([]byte("one\ntwo\nabc\x1b[6n\x1b[0c\x1b[>c"))
+
+	assertEqualsStr(t, "\x1b[2;3R\x1b[?1;2c", responses.String())
+}
+
+func TestResponseError(t *testing.T) {
+	terminal := NewTerminal(WithResponseWriter(failingWriter{}))
+	_, err := terminal.Write([]byte("\x1b[6n"))
+
+	assertTrue(t, err != nil)
+	_, err = terminal.Write([]byte("ok"))
+	assertTrue(t, err == nil)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestTitle(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte("\x1b]0;both\x07one\x1b]2;tit"))
+	terminal.Write([]byte("le\x1b\\ two\x1b]1;icon\x1b\\\x1bPq#0\x1b\\\x1b_apc\x1b\\"))
+
+	assertEqualsStr(t, "one two", strings.Join(terminal.Lines(), ","))
+	assertEqualsStr(t, "title", terminal.Title())
+	assertEqualsStr(t, "icon", terminal.IconName())
+}
+
+func TestTitleStack(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte("\x1b]0;shell\x07\x1b[22;0t\x1b]0;vim\x07"))
+	assertEqualsStr(t, "vim", terminal.Title())
+	terminal.Write([]byte("\x1b[22t\x1b]0;less\x07\x1b[23;2t"))
+	assertEqualsStr(t, "vim", terminal.Title())
+	assertEqualsStr(t, "less", terminal.IconName())
+	terminal.Write([]byte("\x1b[23;0t\x1b[23;0t"))
+
+	assertEqualsStr(t, "shell", terminal.Title())
+	assertEqualsStr(t, "shell", terminal.IconName())
+}
+
+func TestWorkingDirectory(t *testing.T) {
+	terminal := NewTerminal()
+	terminal.Write([]byte("\x1b]7;file://host/home/me/my%20dir\x1b\\"))
+	assertEqualsStr(t, "/home/me/my dir", terminal.WorkingDirectory())
+	terminal.Write([]byte("\x1b]1337;CurrentDir=/tmp\x07"))
+	assertEqualsStr(t, "/tmp", terminal.WorkingDirectory())
+}
+
+func TestOSCHidden(t *testing.T) {
+	lines := captureStringReader(strReader("a\x1b]0;title\x07b\x1b]7;file:///\x1b\\c\n"))
+
+	assertEqualsStr(t, "abc", strings.Join(lines, ","))
+}
+
 func strReader(str string) stringReader {
 	return bufio.NewReader(strings.NewReader(str))
 }
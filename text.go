@@ -0,0 +1,59 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import "strings"
+
+// JoinWrapped makes lines which wrapped at the last column one line again, like when copying
+// text from a terminal. Only has an effect with a width (WithSize).
+func JoinWrapped() Option {
+	return func(o *opt) {
+		o.joinWrapped = true
+	}
+}
+
+// Text returns the text of all lines, without styles, separated by newlines
+func (terminal *Terminal) Text() string {
+	return strings.Join(stripStyles(terminal.Lines()), "\n")
+}
+
+// joined returns rows starting at from (with line size codes, see sized), with wrapped rows joined
+func (terminal *Terminal) joined(from int) []string {
+	rows := terminal.sized(from)
+	if !terminal.opt.joinWrapped {
+		return rows
+	}
+	lines := []string{}
+	for i := 0; i < len(rows); i++ {
+		line := rows[i]
+		for y := from + i; terminal.wrapped[y] && i+1 < len(rows); y++ {
+			line += strings.Repeat(" ", max(0, terminal.lineWidth(y)-length(rows[i])))
+			i++
+			line += rows[i]
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// joinedPosition returns the column and line, in the lines returned by joined(0), of column x of
+// row y
+func (terminal *Terminal) joinedPosition(x, y int) (int, int) {
+	if !terminal.opt.joinWrapped {
+		return x, y
+	}
+	line, offset := 0, 0
+	for row := 0; row < y; row++ {
+		if terminal.wrapped[row] && row+1 < len(terminal.screen) {
+			offset += max(terminal.lineWidth(row), length(terminal.screen[row]))
+		} else {
+			line, offset = line+1, 0
+		}
+	}
+	return offset + x, line
+}
@@ -0,0 +1,33 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJoinWrapped(t *testing.T) {
+	input := "a long line wraps\nshort\nexactly10!\nnext"
+
+	assertEqualsStr(t, "a long lin,e wraps,short,exactly10!,next",
+		strings.Join(Capture(strings.NewReader(input), WithSize(10, 0)), ","))
+	assertEqualsStr(t, "a long line wraps,short,exactly10!,next",
+		strings.Join(Capture(strings.NewReader(input), WithSize(10, 0), JoinWrapped()), ","))
+}
+
+func TestText(t *testing.T) {
+	terminal := NewTerminal(WithSize(5, 2), JoinWrapped())
+	terminal.Write([]byte("\x1b[1mbold\x1b[m text\r\nend"))
+
+	assertEqualsStr(t, "bold text\nend", terminal.Text())
+	assertEqualsStr(t, "text,end", strings.Join(stripStyles(terminal.Screen()), ","))
+
+	terminal.Write([]byte("\x1b[1;1H\x1b[K"))
+	assertEqualsStr(t, "bold \nend", terminal.Text())
+}
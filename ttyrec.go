@@ -0,0 +1,46 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ReadTtyrec reads a ttyrec recording. Each frame is a header of seconds, microseconds and length
+// (32 bit little-endian) followed by the output. Times are relative to the first frame.
+func ReadTtyrec(reader io.Reader) (Recording, error) {
+	recording := Recording{}
+	var start time.Duration
+	for {
+		var header [3]uint32
+		err := binary.Read(reader, binary.LittleEndian, &header)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return recording, fmt.Errorf("reading ttyrec frame %d header: %w", len(recording.Events)+1, err)
+		}
+		var data bytes.Buffer // grown as read, as the length may be corrupt
+		if _, err := io.CopyN(&data, reader, int64(header[2])); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return recording, fmt.Errorf("reading ttyrec frame %d: %w", len(recording.Events)+1, err)
+		}
+		t := time.Duration(header[0])*time.Second + time.Duration(header[1])*time.Microsecond
+		if len(recording.Events) == 0 {
+			start = t
+		}
+		recording.Events = append(recording.Events, Event{Time: t - start, Data: data.String()})
+	}
+	return recording, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func ttyrecFrame(seconds, microseconds uint32, data string) []byte {
+	frame := binary.LittleEndian.AppendUint32(nil, seconds)
+	frame = binary.LittleEndian.AppendUint32(frame, microseconds)
+	frame = binary.LittleEndian.AppendUint32(frame, uint32(len(data)))
+	return append(frame, data...)
+}
+
+func TestReadTtyrec(t *testing.T) {
+	input := append(ttyrecFrame(1000, 500000, "\x1b[2Jhello"), ttyrecFrame(1002, 0, "\x1b[5D\x1b[1mworld")...)
+	recording, err := ReadTtyrec(bytes.NewReader(input))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertEquals(t, 2, len(recording.Events))
+	assertEquals(t, 0, int(recording.Events[0].Time))
+	assertEquals(t, int(1500*time.Millisecond), int(recording.Events[1].Time))
+	frames := recording.Frames(StripStyling())
+	assertEqualsStr(t, "hello", strings.Join(frames[0].Lines, ","))
+	assertEqualsStr(t, "world", strings.Join(frames[1].Lines, ","))
+}
+
+func TestReadTtyrecTruncated(t *testing.T) {
+	input := ttyrecFrame(1, 0, "hello")
+	for _, size := range []int{5, len(input) - 1} {
+		_, err := ReadTtyrec(bytes.NewReader(input[:size]))
+		if err == nil {
+			t.Errorf("Expected error for %d bytes", size)
+		}
+	}
+}
+
+func TestReadTtyrecCorruptLength(t *testing.T) {
+	input := binary.LittleEndian.AppendUint32(ttyrecFrame(1, 0, "")[:8], 0xffffffff)
+	_, err := ReadTtyrec(bytes.NewReader(append(input, "short"...)))
+
+	assertTrue(t, errors.Is(err, io.ErrUnexpectedEOF))
+}
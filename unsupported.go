@@ -0,0 +1,24 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+// WithUnknownHandler calls handler with every escape sequence which is ignored, because it is not
+// known or not supported (e.g. a mode the terminal profile lacks)
+func WithUnknownHandler(handler func(seq Sequence)) Option {
+	return func(o *opt) {
+		o.unknown = handler
+	}
+}
+
+// unsupported reports the sequence being handled as ignored
+func (terminal *Terminal) unsupported() {
+	terminal.ignored = true
+	if terminal.opt.unknown != nil {
+		terminal.opt.unknown(terminal.sequence)
+	}
+}
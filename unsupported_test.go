@@ -0,0 +1,41 @@
+// Copyright (C) 2021-2023 Richard H. Tingstad
+// This program is free software: you can redistribute it and/or modify it under the terms of the
+// GNU General Public License as published by the Free Software Foundation, version 3.
+// This program is distributed in the hope that it will be useful, but WITHOUT ANY WARRANTY;
+// without even the implied warranty of MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.
+// See the GNU General Public License for more details.
+
+package termscreen
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnknownHandler(t *testing.T) {
+	ignored := []Sequence{}
+	handler := WithUnknownHandler(func(seq Sequence) { ignored = append(ignored, seq) })
+	lines := Capture(strings.NewReader("a\x1b[5ib\x1b[1mc\x1b[m\n\x1b]99;x\x07\x1bPq\x1b\\\x1b[?1h\x1b[?9999h\x1b~"), handler)
+
+	assertEqualsStr(t, "ab\x1b[1mc\x1b[m", lines[0])
+	texts, offsets := []string{}, []int{}
+	for _, seq := range ignored {
+		texts, offsets = append(texts, seq.Text), append(offsets, seq.Offset)
+	}
+	assertEqualsStr(t, `["\x1b[5i" "\x1b]99;x\a" "\x1bPq\x1b\\" "\x1b[?9999h" "\x1b~"]`, fmt.Sprintf("%q", texts))
+	assertEqualsStr(t, "[1 15 22 32 40]", fmt.Sprint(offsets))
+}
+
+func TestUnknownHandlerOffsetAcrossWrites(t *testing.T) {
+	ignored := []Sequence{}
+	terminal := NewTerminal(WithUnknownHandler(func(seq Sequence) { ignored = append(ignored, seq) }))
+	terminal.Write([]byte("ab\ncd\x1b["))
+	terminal.Write([]byte("5i\x1b[20;4h"))
+
+	assertEquals(t, 2, len(ignored))
+	assertEquals(t, 5, ignored[0].Offset)
+	assertEqualsStr(t, "\x1b[5i", ignored[0].Text)
+	assertEquals(t, 9, ignored[1].Offset)
+	assertTrue(t, terminal.newLineMode)
+}